@@ -0,0 +1,88 @@
+package connect
+
+import (
+	"context"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+// defaultActivityPageSize is the number of API requests an APIActivityIterator fetches at once if no page size
+// is given. It matches the default limit of the Connect API.
+const defaultActivityPageSize = 50
+
+// APIActivityIterator pages through the API requests made to Connect, fetching a page of requests with
+// GetAPIActivityWithContext whenever the previous page has been read.
+//
+//	it := connect.NewAPIActivityIterator(ctx, client, 100)
+//	for it.Next() {
+//		request := it.Request()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type APIActivityIterator struct {
+	ctx      context.Context
+	client   Client
+	pageSize int
+
+	page    []onepassword.APIRequest
+	index   int
+	offset  int
+	done    bool
+	err     error
+	current *onepassword.APIRequest
+}
+
+// NewAPIActivityIterator returns an iterator over all API requests made to Connect, which fetches pageSize
+// requests at a time. A pageSize of 0 or lower uses the default page size of the Connect API.
+func NewAPIActivityIterator(ctx context.Context, client Client, pageSize int) *APIActivityIterator {
+	if pageSize <= 0 {
+		pageSize = defaultActivityPageSize
+	}
+	return &APIActivityIterator{
+		ctx:      ctx,
+		client:   client,
+		pageSize: pageSize,
+	}
+}
+
+// Next advances the iterator to the next API request, which can then be retrieved with Request. It returns false
+// when there are no more requests, or fetching a page failed, in which case Err returns the error.
+func (it *APIActivityIterator) Next() bool {
+	it.current = nil
+	if it.err != nil {
+		return false
+	}
+	if it.index >= len(it.page) {
+		if it.done {
+			return false
+		}
+		page, err := it.client.GetAPIActivityWithContext(it.ctx, it.pageSize, it.offset)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		it.page = page
+		it.index = 0
+		it.offset += len(page)
+		// A page that is not full is the last one
+		it.done = len(page) < it.pageSize
+		if len(page) == 0 {
+			return false
+		}
+	}
+	it.current = &it.page[it.index]
+	it.index++
+	return true
+}
+
+// Request returns the API request the iterator is at, or nil if Next has not been called or returned false.
+func (it *APIActivityIterator) Request() *onepassword.APIRequest {
+	return it.current
+}
+
+// Err returns the error that occurred while fetching a page of API requests, if any.
+func (it *APIActivityIterator) Err() error {
+	return it.err
+}
@@ -0,0 +1,79 @@
+package connect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+// serveActivity serves total API requests with the limit and offset of each request, recording the requested pages.
+func serveActivity(total int, pages *[][2]int) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		limit, _ := strconv.Atoi(req.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(req.URL.Query().Get("offset"))
+		*pages = append(*pages, [2]int{limit, offset})
+
+		requests := []onepassword.APIRequest{}
+		for i := offset; i < total && i < offset+limit; i++ {
+			requests = append(requests, onepassword.APIRequest{RequestID: fmt.Sprintf("request-%d", i)})
+		}
+		body, _ := json.Marshal(requests)
+		return &http.Response{
+			Status:     http.StatusText(http.StatusOK),
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+			Header:     http.Header{},
+		}, nil
+	}
+}
+
+func TestAPIActivityIterator(t *testing.T) {
+	var pages [][2]int
+	mockHTTPClient.Dofunc = serveActivity(5, &pages)
+
+	it := NewAPIActivityIterator(context.Background(), testClient, 2)
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Request().RequestID)
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Nil(t, it.Request())
+	assert.Equal(t, []string{"request-0", "request-1", "request-2", "request-3", "request-4"}, ids)
+	assert.Equal(t, [][2]int{{2, 0}, {2, 2}, {2, 4}}, pages)
+}
+
+func TestAPIActivityIteratorFullLastPage(t *testing.T) {
+	var pages [][2]int
+	mockHTTPClient.Dofunc = serveActivity(4, &pages)
+
+	it := NewAPIActivityIterator(context.Background(), testClient, 2)
+	count := 0
+	for it.Next() {
+		count++
+	}
+
+	assert.Nil(t, it.Err())
+	assert.Equal(t, 4, count)
+	assert.Equal(t, [][2]int{{2, 0}, {2, 2}, {2, 4}}, pages)
+}
+
+func TestAPIActivityIteratorError(t *testing.T) {
+	errResult := apiError(http.StatusUnauthorized, "Invalid token")
+	mockHTTPClient.Dofunc = respondError(errResult)
+
+	it := NewAPIActivityIterator(context.Background(), testClient, 0)
+
+	assert.False(t, it.Next())
+	assert.ErrorIs(t, it.Err(), errResult)
+	assert.False(t, it.Next())
+}
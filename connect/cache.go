@@ -0,0 +1,866 @@
+package connect
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+const (
+	defaultCacheVaultTTL   = 10 * time.Minute
+	defaultCacheItemTTL    = time.Minute
+	defaultCacheMaxEntries = 1000
+
+	minRecoveryBackoff = time.Second
+	maxRecoveryBackoff = time.Minute
+
+	// defaultSnapshotDelay is how long changes to the cache are collected before the snapshot is written
+	defaultSnapshotDelay = time.Second
+)
+
+// CacheOption configures the CachingClient returned by NewCachingClient.
+type CacheOption func(*CachingClient)
+
+// WithVaultTTL sets how long vaults are cached. This also applies to the resolution of vault titles to UUIDs.
+// A TTL of 0 or lower disables caching vaults. Defaults to 10 minutes.
+func WithVaultTTL(ttl time.Duration) CacheOption {
+	return func(c *CachingClient) {
+		c.vaultTTL = ttl
+	}
+}
+
+// WithItemTTL sets how long items are cached. A TTL of 0 or lower disables caching items. Defaults to 1 minute.
+func WithItemTTL(ttl time.Duration) CacheOption {
+	return func(c *CachingClient) {
+		c.itemTTL = ttl
+	}
+}
+
+// WithMaxCacheEntries sets the maximum number of vaults and items kept in the cache.
+// When the cache is full, the least recently used entry is evicted. Defaults to 1000.
+func WithMaxCacheEntries(maxEntries int) CacheOption {
+	return func(c *CachingClient) {
+		c.maxEntries = maxEntries
+	}
+}
+
+// WithStaleWhileRevalidate makes the cache serve an expired vault or item for up to window after it expired,
+// while it is refreshed in the background.
+func WithStaleWhileRevalidate(window time.Duration) CacheOption {
+	return func(c *CachingClient) {
+		c.staleWindow = window
+	}
+}
+
+// WithOfflineFallback makes the cache serve the last known good vault or item when Connect cannot be reached or
+// responds with a 5xx status, as long as it was retrieved from Connect less than maxAge ago. A maxAge of 0 places
+// no limit on the age. The entry is refreshed in the background until Connect is available again.
+func WithOfflineFallback(maxAge time.Duration) CacheOption {
+	return func(c *CachingClient) {
+		c.offlineFallback = true
+		c.fallbackMaxAge = maxAge
+	}
+}
+
+// WithSnapshotFile persists the cache to the file at path, encrypted with AES-GCM using key, which must be 16, 24
+// or 32 bytes long. The snapshot is restored when the CachingClient is created, so that the offline fallback can
+// serve items retrieved before a restart, and is written in the background shortly after the cache changes and
+// when the CachingClient is closed. The snapshot is best effort: if it cannot be read, the cache starts empty, and
+// if it cannot be written, the error is reported by SnapshotError.
+func WithSnapshotFile(path string, key []byte) CacheOption {
+	return func(c *CachingClient) {
+		c.snapshotPath = path
+		c.snapshotKey = key
+	}
+}
+
+// WithCacheMetrics sets the MetricsRecorder that receives the hits and misses of the cache.
+func WithCacheMetrics(recorder MetricsRecorder) CacheOption {
+	return func(c *CachingClient) {
+		c.metrics = recorder
+	}
+}
+
+// CachingClient is a Client that keeps the vaults and items it retrieves through the wrapped Client in memory,
+// so that repeated lookups and title resolutions do not result in requests to Connect.
+// Cached items are invalidated when they are updated or deleted through the CachingClient. Changes made by
+// others are picked up once the cached entry expires, or after calling Invalidate.
+type CachingClient struct {
+	Client
+
+	vaultTTL        time.Duration
+	itemTTL         time.Duration
+	maxEntries      int
+	staleWindow     time.Duration
+	offlineFallback bool
+	fallbackMaxAge  time.Duration
+	snapshotPath    string
+	snapshotKey     []byte
+	metrics         MetricsRecorder
+	now             func() time.Time
+	recoveryBackoff time.Duration
+
+	mu         sync.Mutex
+	entries    map[string]*list.Element
+	lru        *list.List
+	refreshing map[string]bool
+
+	snapshotMu    sync.Mutex
+	snapshotDelay time.Duration
+	snapshotDirty chan struct{}
+	snapshotDone  chan struct{}
+	snapshotErr   error
+	background    context.Context
+	stop          context.CancelFunc
+}
+
+type cacheEntry struct {
+	key       string
+	vault     *onepassword.Vault
+	item      *onepassword.Item
+	vaultUUID string
+	fetched   time.Time
+	ttl       time.Duration
+}
+
+// CachedItem is an item served by the CachingClient, along with how fresh it is.
+type CachedItem struct {
+	*onepassword.Item
+	// Stale is true if the item expired, and was served because Connect could not be reached or while it is
+	// being refreshed in the background.
+	Stale bool
+	// Age is the time since the item was retrieved from Connect.
+	Age time.Duration
+}
+
+// NewCachingClient returns a CachingClient that caches the vaults and items retrieved with client, or an error if
+// the options are invalid, such as a snapshot key of the wrong length.
+func NewCachingClient(client Client, opts ...CacheOption) (*CachingClient, error) {
+	c := &CachingClient{
+		Client:          client,
+		vaultTTL:        defaultCacheVaultTTL,
+		itemTTL:         defaultCacheItemTTL,
+		maxEntries:      defaultCacheMaxEntries,
+		now:             time.Now,
+		recoveryBackoff: minRecoveryBackoff,
+		entries:         map[string]*list.Element{},
+		lru:             list.New(),
+		refreshing:      map[string]bool{},
+		snapshotDelay:   defaultSnapshotDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.snapshotPath != "" {
+		if _, err := snapshotCipher(c.snapshotKey); err != nil {
+			return nil, err
+		}
+	}
+
+	c.background, c.stop = context.WithCancel(context.Background())
+	if c.snapshotPath != "" {
+		c.restoreSnapshot()
+		c.snapshotDirty = make(chan struct{}, 1)
+		c.snapshotDone = make(chan struct{})
+		go c.writeSnapshots()
+	}
+	return c, nil
+}
+
+// Close stops the refreshes running in the background, and writes the snapshot if the cache changed since it was
+// last written. The CachingClient can still be used afterwards, but expired entries are no longer refreshed and
+// the snapshot is no longer written in the background.
+func (c *CachingClient) Close() {
+	c.stop()
+	if c.snapshotDone != nil {
+		<-c.snapshotDone
+	}
+}
+
+// Invalidate removes the item identified by itemQuery (title or UUID) in the vault identified by vaultQuery
+// (title or UUID) from the cache. If the vault title is not cached, the item is invalidated in all vaults.
+func (c *CachingClient) Invalidate(vaultQuery string, itemQuery string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	vaultUUID := vaultQuery
+	if !isValidUUID(vaultQuery) {
+		vaultUUID = ""
+		if vault, ok := c.getLocked(vaultCacheKey(vaultQuery)); ok {
+			vaultUUID = vault.vault.ID
+		}
+	}
+	c.invalidateLocked(vaultUUID, itemQuery)
+}
+
+// InvalidateAll removes all vaults and items from the cache.
+func (c *CachingClient) InvalidateAll() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = map[string]*list.Element{}
+	c.lru.Init()
+	c.markSnapshotDirty()
+}
+
+func (c *CachingClient) GetVault(vaultQuery string) (*onepassword.Vault, error) {
+	return c.GetVaultWithContext(context.Background(), vaultQuery)
+}
+
+func (c *CachingClient) GetVaultWithContext(ctx context.Context, vaultQuery string) (*onepassword.Vault, error) {
+	return c.cachedVault(ctx, vaultQuery, func(ctx context.Context) (*onepassword.Vault, error) {
+		return c.Client.GetVaultWithContext(ctx, vaultQuery)
+	})
+}
+
+func (c *CachingClient) GetVaultByUUID(uuid string) (*onepassword.Vault, error) {
+	return c.GetVaultByUUIDWithContext(context.Background(), uuid)
+}
+
+func (c *CachingClient) GetVaultByUUIDWithContext(ctx context.Context, uuid string) (*onepassword.Vault, error) {
+	return c.cachedVault(ctx, uuid, func(ctx context.Context) (*onepassword.Vault, error) {
+		return c.Client.GetVaultByUUIDWithContext(ctx, uuid)
+	})
+}
+
+func (c *CachingClient) GetVaultByTitle(title string) (*onepassword.Vault, error) {
+	return c.GetVaultByTitleWithContext(context.Background(), title)
+}
+
+func (c *CachingClient) GetVaultByTitleWithContext(ctx context.Context, title string) (*onepassword.Vault, error) {
+	return c.cachedVault(ctx, title, func(ctx context.Context) (*onepassword.Vault, error) {
+		return c.Client.GetVaultByTitleWithContext(ctx, title)
+	})
+}
+
+func (c *CachingClient) GetItems(vaultQuery string) ([]onepassword.Item, error) {
+	return c.GetItemsWithContext(context.Background(), vaultQuery)
+}
+
+func (c *CachingClient) GetItemsWithContext(ctx context.Context, vaultQuery string, opts ...ListOption) ([]onepassword.Item, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.GetItemsWithContext(ctx, vaultUUID, opts...)
+}
+
+func (c *CachingClient) GetItemSummaries(vaultQuery string) ([]onepassword.ItemSummary, error) {
+	return c.GetItemSummariesWithContext(context.Background(), vaultQuery)
+}
+
+func (c *CachingClient) GetItemSummariesWithContext(ctx context.Context, vaultQuery string, opts ...ListOption) ([]onepassword.ItemSummary, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.GetItemSummariesWithContext(ctx, vaultUUID, opts...)
+}
+
+func (c *CachingClient) GetItem(itemQuery string, vaultQuery string) (*onepassword.Item, error) {
+	return c.GetItemWithContext(context.Background(), itemQuery, vaultQuery)
+}
+
+func (c *CachingClient) GetItemWithContext(ctx context.Context, itemQuery string, vaultQuery string) (*onepassword.Item, error) {
+	cached, err := c.GetCachedItemWithContext(ctx, itemQuery, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	return cached.Item, nil
+}
+
+// GetCachedItem Get a specific Item by either title or UUID, reporting whether it was served stale from the cache
+func (c *CachingClient) GetCachedItem(itemQuery string, vaultQuery string) (*CachedItem, error) {
+	return c.GetCachedItemWithContext(context.Background(), itemQuery, vaultQuery)
+}
+
+// GetCachedItemWithContext Get a specific Item by either title or UUID, reporting whether it was served stale from
+// the cache
+func (c *CachingClient) GetCachedItemWithContext(ctx context.Context, itemQuery string, vaultQuery string) (*CachedItem, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	return c.lookupItem(ctx, vaultUUID, itemQuery, func(ctx context.Context) (*onepassword.Item, error) {
+		return c.Client.GetItemWithContext(ctx, itemQuery, vaultUUID)
+	})
+}
+
+func (c *CachingClient) GetItemByUUID(uuid string, vaultQuery string) (*onepassword.Item, error) {
+	return c.GetItemByUUIDWithContext(context.Background(), uuid, vaultQuery)
+}
+
+func (c *CachingClient) GetItemByUUIDWithContext(ctx context.Context, uuid string, vaultQuery string) (*onepassword.Item, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	return c.cachedItem(ctx, vaultUUID, uuid, func(ctx context.Context) (*onepassword.Item, error) {
+		return c.Client.GetItemByUUIDWithContext(ctx, uuid, vaultUUID)
+	})
+}
+
+func (c *CachingClient) GetItemByTitle(title string, vaultQuery string) (*onepassword.Item, error) {
+	return c.GetItemByTitleWithContext(context.Background(), title, vaultQuery)
+}
+
+func (c *CachingClient) GetItemByTitleWithContext(ctx context.Context, title string, vaultQuery string) (*onepassword.Item, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	return c.cachedItem(ctx, vaultUUID, title, func(ctx context.Context) (*onepassword.Item, error) {
+		return c.Client.GetItemByTitleWithContext(ctx, title, vaultUUID)
+	})
+}
+
+func (c *CachingClient) GetItemsByTitle(title string, vaultQuery string) ([]onepassword.Item, error) {
+	return c.GetItemsByTitleWithContext(context.Background(), title, vaultQuery)
+}
+
+func (c *CachingClient) GetItemsByTitleWithContext(ctx context.Context, title string, vaultQuery string) ([]onepassword.Item, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.GetItemsByTitleWithContext(ctx, title, vaultUUID)
+}
+
+func (c *CachingClient) GetItemsByID(vaultQuery string, ids []string) ([]onepassword.Item, error) {
+	return c.GetItemsByIDWithContext(context.Background(), vaultQuery, ids)
+}
+
+func (c *CachingClient) GetItemsByIDWithContext(ctx context.Context, vaultQuery string, ids []string) ([]onepassword.Item, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.GetItemsByIDWithContext(ctx, vaultUUID, ids)
+}
+
+func (c *CachingClient) GetItemsFull(vaultQuery string) ([]onepassword.Item, error) {
+	return c.GetItemsFullWithContext(context.Background(), vaultQuery)
+}
+
+func (c *CachingClient) GetItemsFullWithContext(ctx context.Context, vaultQuery string) ([]onepassword.Item, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.GetItemsFullWithContext(ctx, vaultUUID)
+}
+
+func (c *CachingClient) CreateItem(item *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	return c.CreateItemWithContext(context.Background(), item, vaultQuery)
+}
+
+func (c *CachingClient) CreateItemWithContext(ctx context.Context, item *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	created, err := c.Client.CreateItemWithContext(ctx, item, vaultUUID)
+	if err != nil {
+		return nil, err
+	}
+	// A cached item with the same title can no longer be looked up unambiguously by its title
+	c.invalidate(vaultUUID, created.Title)
+	return created, nil
+}
+
+func (c *CachingClient) UpdateItem(item *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	return c.UpdateItemWithContext(context.Background(), item, vaultQuery)
+}
+
+func (c *CachingClient) UpdateItemWithContext(ctx context.Context, item *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	defer c.invalidate(item.Vault.ID, item.ID)
+	return c.Client.UpdateItemWithContext(ctx, item, vaultQuery)
+}
+
+func (c *CachingClient) PatchItem(itemQuery string, vaultQuery string, ops []onepassword.PatchOperation) (*onepassword.Item, error) {
+	return c.PatchItemWithContext(context.Background(), itemQuery, vaultQuery, ops)
+}
+
+func (c *CachingClient) PatchItemWithContext(ctx context.Context, itemQuery string, vaultQuery string, ops []onepassword.PatchOperation) (*onepassword.Item, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer c.invalidate(vaultUUID, itemQuery)
+	patched, err := c.Client.PatchItemWithContext(ctx, itemQuery, vaultUUID, ops)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(vaultUUID, patched.ID)
+	return patched, nil
+}
+
+func (c *CachingClient) UpdateItemDiff(original *onepassword.Item, modified *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	return c.UpdateItemDiffWithContext(context.Background(), original, modified, vaultQuery)
+}
+
+func (c *CachingClient) UpdateItemDiffWithContext(ctx context.Context, original *onepassword.Item, modified *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	if original == nil {
+		return c.Client.UpdateItemDiffWithContext(ctx, original, modified, vaultQuery)
+	}
+	defer c.invalidate(original.Vault.ID, original.ID)
+	return c.Client.UpdateItemDiffWithContext(ctx, original, modified, vaultQuery)
+}
+
+func (c *CachingClient) UpdateItemChecked(item *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	return c.UpdateItemCheckedWithContext(context.Background(), item, vaultQuery)
+}
+
+func (c *CachingClient) UpdateItemCheckedWithContext(ctx context.Context, item *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	// A conflict means the cached item is outdated as well, so it is invalidated on any outcome
+	defer c.invalidate(item.Vault.ID, item.ID)
+	return c.Client.UpdateItemCheckedWithContext(ctx, item, vaultQuery)
+}
+
+func (c *CachingClient) ModifyItem(itemQuery string, vaultQuery string, modify func(*onepassword.Item) error) (*onepassword.Item, error) {
+	return c.ModifyItemWithContext(context.Background(), itemQuery, vaultQuery, modify)
+}
+
+// ModifyItemWithContext always reads the item from Connect, as modifying a cached copy would only cause a conflict.
+func (c *CachingClient) ModifyItemWithContext(ctx context.Context, itemQuery string, vaultQuery string, modify func(*onepassword.Item) error) (*onepassword.Item, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	defer c.invalidate(vaultUUID, itemQuery)
+	modified, err := c.Client.ModifyItemWithContext(ctx, itemQuery, vaultUUID, modify)
+	if err != nil {
+		return nil, err
+	}
+	c.invalidate(vaultUUID, modified.ID)
+	return modified, nil
+}
+
+func (c *CachingClient) DeleteItem(item *onepassword.Item, vaultQuery string) error {
+	return c.DeleteItemWithContext(context.Background(), item, vaultQuery)
+}
+
+func (c *CachingClient) DeleteItemWithContext(ctx context.Context, item *onepassword.Item, vaultQuery string) error {
+	defer c.invalidate(item.Vault.ID, item.ID)
+	return c.Client.DeleteItemWithContext(ctx, item, vaultQuery)
+}
+
+func (c *CachingClient) DeleteItemByID(itemUUID string, vaultQuery string) error {
+	return c.DeleteItemByIDWithContext(context.Background(), itemUUID, vaultQuery)
+}
+
+func (c *CachingClient) DeleteItemByIDWithContext(ctx context.Context, itemUUID string, vaultQuery string) error {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return err
+	}
+	defer c.invalidate(vaultUUID, itemUUID)
+	return c.Client.DeleteItemByIDWithContext(ctx, itemUUID, vaultUUID)
+}
+
+func (c *CachingClient) DeleteItemByTitle(title string, vaultQuery string) error {
+	return c.DeleteItemByTitleWithContext(context.Background(), title, vaultQuery)
+}
+
+func (c *CachingClient) DeleteItemByTitleWithContext(ctx context.Context, title string, vaultQuery string) error {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return err
+	}
+	defer c.invalidate(vaultUUID, title)
+	return c.Client.DeleteItemByTitleWithContext(ctx, title, vaultUUID)
+}
+
+func (c *CachingClient) GetFiles(itemQuery string, vaultQuery string) ([]onepassword.File, error) {
+	return c.GetFilesWithContext(context.Background(), itemQuery, vaultQuery)
+}
+
+func (c *CachingClient) GetFilesWithContext(ctx context.Context, itemQuery string, vaultQuery string) ([]onepassword.File, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.GetFilesWithContext(ctx, itemQuery, vaultUUID)
+}
+
+func (c *CachingClient) GetFile(uuid string, itemQuery string, vaultQuery string) (*onepassword.File, error) {
+	return c.GetFileWithContext(context.Background(), uuid, itemQuery, vaultQuery)
+}
+
+func (c *CachingClient) GetFileWithContext(ctx context.Context, uuid string, itemQuery string, vaultQuery string) (*onepassword.File, error) {
+	vaultUUID, err := resolveVaultUUID(ctx, c, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	return c.Client.GetFileWithContext(ctx, uuid, itemQuery, vaultUUID)
+}
+
+func (c *CachingClient) LoadStructFromItemByUUID(config interface{}, itemUUID string, vaultQuery string) error {
+	return c.LoadStructFromItemByUUIDWithContext(context.Background(), config, itemUUID, vaultQuery)
+}
+
+func (c *CachingClient) LoadStructFromItemByUUIDWithContext(ctx context.Context, config interface{}, itemUUID string, vaultQuery string) error {
+	return loadStructFromItemByUUID(ctx, c, config, itemUUID, vaultQuery)
+}
+
+func (c *CachingClient) LoadStructFromItemByTitle(config interface{}, itemTitle string, vaultQuery string) error {
+	return c.LoadStructFromItemByTitleWithContext(context.Background(), config, itemTitle, vaultQuery)
+}
+
+func (c *CachingClient) LoadStructFromItemByTitleWithContext(ctx context.Context, config interface{}, itemTitle string, vaultQuery string) error {
+	return loadStructFromItemByTitle(ctx, c, config, itemTitle, vaultQuery)
+}
+
+func (c *CachingClient) LoadStructFromItem(config interface{}, itemQuery string, vaultQuery string) error {
+	return c.LoadStructFromItemWithContext(context.Background(), config, itemQuery, vaultQuery)
+}
+
+func (c *CachingClient) LoadStructFromItemWithContext(ctx context.Context, config interface{}, itemQuery string, vaultQuery string) error {
+	return loadStructFromItem(ctx, c, config, itemQuery, vaultQuery)
+}
+
+func (c *CachingClient) LoadStruct(config interface{}) error {
+	return c.LoadStructWithContext(context.Background(), config)
+}
+
+func (c *CachingClient) LoadStructWithContext(ctx context.Context, config interface{}) error {
+	return loadStruct(ctx, c, config)
+}
+
+// StrictLoadStruct reports whether the wrapped Client loads in strict mode, so that the LoadStruct methods of the
+// cache load the same way.
+func (c *CachingClient) StrictLoadStruct() bool {
+	return strictLoading(c.Client)
+}
+
+func (c *CachingClient) ResolveReference(ref string) (string, error) {
+	return c.ResolveReferenceWithContext(context.Background(), ref)
+}
+
+func (c *CachingClient) ResolveReferenceWithContext(ctx context.Context, ref string) (string, error) {
+	return resolveReference(ctx, c, ref)
+}
+
+// cachedVault returns the vault cached for vaultQuery, or fetches it and adds it to the cache.
+func (c *CachingClient) cachedVault(ctx context.Context, vaultQuery string, fetch func(ctx context.Context) (*onepassword.Vault, error)) (*onepassword.Vault, error) {
+	if c.vaultTTL <= 0 {
+		return fetch(ctx)
+	}
+
+	entry, _, err := c.lookup(ctx, cacheKindVault, vaultCacheKey(vaultQuery), func(ctx context.Context) (*cacheEntry, error) {
+		vault, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		cached := *vault
+		return &cacheEntry{vault: &cached, ttl: c.vaultTTL}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	vault := *entry.vault
+	return &vault, nil
+}
+
+// cachedItem returns the item cached for itemQuery in the given vault, or fetches it and adds it to the cache.
+func (c *CachingClient) cachedItem(ctx context.Context, vaultUUID string, itemQuery string, fetch func(ctx context.Context) (*onepassword.Item, error)) (*onepassword.Item, error) {
+	cached, err := c.lookupItem(ctx, vaultUUID, itemQuery, fetch)
+	if err != nil {
+		return nil, err
+	}
+	return cached.Item, nil
+}
+
+func (c *CachingClient) lookupItem(ctx context.Context, vaultUUID string, itemQuery string, fetch func(ctx context.Context) (*onepassword.Item, error)) (*CachedItem, error) {
+	if c.itemTTL <= 0 {
+		item, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &CachedItem{Item: item}, nil
+	}
+
+	entry, stale, err := c.lookup(ctx, cacheKindItem, itemCacheKey(vaultUUID, itemQuery), func(ctx context.Context) (*cacheEntry, error) {
+		item, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &cacheEntry{item: copyItem(item), vaultUUID: vaultUUID, ttl: c.itemTTL}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CachedItem{
+		Item:  copyItem(entry.item),
+		Stale: stale,
+		Age:   c.now().Sub(entry.fetched),
+	}, nil
+}
+
+// lookup returns the entry cached under key. If there is no fresh entry, it is fetched and added to the cache,
+// unless an expired entry can be served instead: while it is within the stale-while-revalidate window, or if the
+// fetch failed because Connect is unavailable and the offline fallback is enabled. The returned bool is true if
+// the returned entry is expired.
+func (c *CachingClient) lookup(ctx context.Context, kind string, key string, fetch func(ctx context.Context) (*cacheEntry, error)) (*cacheEntry, bool, error) {
+	c.mu.Lock()
+	entry, ok := c.getLocked(key)
+	c.mu.Unlock()
+
+	if ok {
+		age := c.now().Sub(entry.fetched)
+		if age < entry.ttl {
+			c.recordLookup(kind, CacheHit)
+			return entry, false, nil
+		}
+		if age < entry.ttl+c.staleWindow {
+			c.refreshInBackground(key, fetch, false)
+			c.recordLookup(kind, CacheStale)
+			return entry, true, nil
+		}
+	}
+
+	fetched, err := fetch(ctx)
+	if err != nil {
+		if ok && c.canFallBack(entry, err) {
+			c.refreshInBackground(key, fetch, true)
+			c.recordLookup(kind, CacheStale)
+			return entry, true, nil
+		}
+		c.recordLookup(kind, CacheMiss)
+		return nil, false, err
+	}
+	c.store(key, fetched)
+	c.recordLookup(kind, CacheMiss)
+	return fetched, false, nil
+}
+
+func (c *CachingClient) recordLookup(kind string, result CacheLookupResult) {
+	if c.metrics != nil {
+		c.metrics.ObserveCacheLookup(kind, result)
+	}
+}
+
+// canFallBack returns true if the expired entry can be served because fetching it failed with err.
+func (c *CachingClient) canFallBack(entry *cacheEntry, err error) bool {
+	if !c.offlineFallback || !isUnavailable(err) {
+		return false
+	}
+	return c.fallbackMaxAge <= 0 || c.now().Sub(entry.fetched) < c.fallbackMaxAge
+}
+
+// refreshInBackground fetches the entry for key in the background, unless a refresh is already running for it.
+// If untilAvailable is true, the fetch is retried with an increasing backoff for as long as Connect is unavailable.
+func (c *CachingClient) refreshInBackground(key string, fetch func(ctx context.Context) (*cacheEntry, error), untilAvailable bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.refreshing[key] {
+		return
+	}
+	c.refreshing[key] = true
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.refreshing, key)
+			c.mu.Unlock()
+		}()
+
+		backoff := c.recoveryBackoff
+		for {
+			fetched, err := fetch(c.background)
+			if err == nil {
+				c.store(key, fetched)
+				return
+			}
+			if !untilAvailable || !isUnavailable(err) {
+				return
+			}
+			if sleep(c.background, backoff) != nil {
+				return
+			}
+			if backoff *= 2; backoff > maxRecoveryBackoff {
+				backoff = maxRecoveryBackoff
+			}
+		}
+	}()
+}
+
+// store adds the fetched entry to the cache under key, and under the key of the vault's or item's UUID.
+func (c *CachingClient) store(key string, fetched *cacheEntry) {
+	c.mu.Lock()
+	fetched.fetched = c.now()
+	byKey := *fetched
+	byKey.key = key
+	c.putLocked(&byKey)
+	byID := *fetched
+	if fetched.item != nil {
+		byID.key = itemCacheKey(fetched.vaultUUID, fetched.item.ID)
+	} else {
+		byID.key = vaultCacheKey(fetched.vault.ID)
+	}
+	c.putLocked(&byID)
+	c.markSnapshotDirty()
+	c.mu.Unlock()
+}
+
+// markSnapshotDirty makes the snapshot be written in the background, so that it reflects the changes made to the
+// cache. It does not block, so it can be called while c.mu is held.
+func (c *CachingClient) markSnapshotDirty() {
+	if c.snapshotDirty == nil {
+		return
+	}
+	select {
+	case c.snapshotDirty <- struct{}{}:
+	default:
+		// A write is already pending, and will include these changes
+	}
+}
+
+func (c *CachingClient) invalidate(vaultUUID string, itemQuery string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.invalidateLocked(vaultUUID, itemQuery)
+}
+
+// invalidateLocked removes all entries of the item matching itemQuery by UUID or title. If vaultUUID is empty,
+// matching items are removed from all vaults.
+func (c *CachingClient) invalidateLocked(vaultUUID string, itemQuery string) {
+	for key, element := range c.entries {
+		entry := element.Value.(*cacheEntry)
+		if entry.item == nil {
+			continue
+		}
+		if vaultUUID != "" && entry.vaultUUID != vaultUUID {
+			continue
+		}
+		if entry.item.ID == itemQuery || entry.item.Title == itemQuery || key == itemCacheKey(vaultUUID, itemQuery) {
+			c.lru.Remove(element)
+			delete(c.entries, key)
+			// Otherwise a deleted or changed item would be restored from the snapshot after a restart
+			c.markSnapshotDirty()
+		}
+	}
+}
+
+// getLocked returns the entry cached under key, including expired entries that may still be served stale.
+func (c *CachingClient) getLocked(key string) (*cacheEntry, bool) {
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*cacheEntry)
+	if !c.retainable(entry) {
+		c.lru.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.lru.MoveToFront(element)
+	return entry, true
+}
+
+// retainable returns true if the entry is fresh, or may still be served stale.
+func (c *CachingClient) retainable(entry *cacheEntry) bool {
+	age := c.now().Sub(entry.fetched)
+	if age < entry.ttl+c.staleWindow {
+		return true
+	}
+	return c.offlineFallback && (c.fallbackMaxAge <= 0 || age < c.fallbackMaxAge)
+}
+
+func (c *CachingClient) putLocked(entry *cacheEntry) {
+	if element, ok := c.entries[entry.key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+	c.entries[entry.key] = c.lru.PushFront(entry)
+
+	for c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// isUnavailable returns true if err indicates that Connect could not be reached or failed to process the request.
+func isUnavailable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var apiErr *onepassword.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+func vaultCacheKey(vaultQuery string) string {
+	return fmt.Sprintf("vault/%s", vaultQuery)
+}
+
+func itemCacheKey(vaultUUID string, itemQuery string) string {
+	return fmt.Sprintf("item/%s/%s", vaultUUID, itemQuery)
+}
+
+// copyItem returns a deep copy of the item, so that modifications made by the receiver of an item do not affect
+// other copies of it.
+func copyItem(item *onepassword.Item) *onepassword.Item {
+	if item == nil {
+		return nil
+	}
+	c := *item
+	c.URLs = append([]onepassword.ItemURL(nil), item.URLs...)
+	c.Tags = append([]string(nil), item.Tags...)
+	if item.Sections != nil {
+		c.Sections = make([]*onepassword.ItemSection, len(item.Sections))
+		for i, s := range item.Sections {
+			if s != nil {
+				section := *s
+				c.Sections[i] = &section
+			}
+		}
+	}
+	if item.Fields != nil {
+		c.Fields = make([]*onepassword.ItemField, len(item.Fields))
+		for i, f := range item.Fields {
+			if f == nil {
+				continue
+			}
+			field := *f
+			if f.Section != nil {
+				section := *f.Section
+				field.Section = &section
+			}
+			if f.Recipe != nil {
+				recipe := *f.Recipe
+				recipe.CharacterSets = append([]string(nil), f.Recipe.CharacterSets...)
+				field.Recipe = &recipe
+			}
+			c.Fields[i] = &field
+		}
+	}
+	if item.Files != nil {
+		c.Files = make([]*onepassword.File, len(item.Files))
+		for i, f := range item.Files {
+			if f == nil {
+				continue
+			}
+			file := *f
+			if f.Section != nil {
+				section := *f.Section
+				file.Section = &section
+			}
+			c.Files[i] = &file
+		}
+	}
+	return &c
+}
@@ -0,0 +1,180 @@
+package connect
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+// snapshotEntry is the representation of a cache entry in a snapshot file.
+type snapshotEntry struct {
+	Key       string             `json:"key"`
+	Vault     *onepassword.Vault `json:"vault,omitempty"`
+	Item      *onepassword.Item  `json:"item,omitempty"`
+	VaultUUID string             `json:"vaultUUID,omitempty"`
+	Fetched   time.Time          `json:"fetched"`
+	TTL       time.Duration      `json:"ttl"`
+}
+
+// SaveSnapshot writes the cached vaults and items to the snapshot file configured with WithSnapshotFile.
+// This happens automatically in the background after the cache is updated, but can be called to write the snapshot
+// right away, or to check that it can be written.
+func (c *CachingClient) SaveSnapshot() error {
+	if c.snapshotPath == "" {
+		return errors.New("no snapshot file configured")
+	}
+
+	c.mu.Lock()
+	entries := make([]snapshotEntry, 0, c.lru.Len())
+	for element := c.lru.Back(); element != nil; element = element.Prev() {
+		entry := element.Value.(*cacheEntry)
+		entries = append(entries, snapshotEntry{
+			Key:       entry.key,
+			Vault:     entry.vault,
+			Item:      entry.item,
+			VaultUUID: entry.vaultUUID,
+			Fetched:   entry.fetched,
+			TTL:       entry.ttl,
+		})
+	}
+	plaintext, err := json.Marshal(entries)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := sealSnapshot(c.snapshotKey, plaintext)
+	if err != nil {
+		return err
+	}
+
+	c.snapshotMu.Lock()
+	defer c.snapshotMu.Unlock()
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.snapshotPath), filepath.Base(c.snapshotPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(ciphertext); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.snapshotPath)
+}
+
+// SnapshotError returns the error of the last snapshot written in the background, or nil if it was written
+// successfully or no snapshot has been written yet.
+func (c *CachingClient) SnapshotError() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.snapshotErr
+}
+
+// writeSnapshots writes the snapshot whenever the cache changed, until the CachingClient is closed. Changes made
+// within snapshotDelay of each other are written at once, so that the cache is not encrypted and written to disk
+// for every entry that is fetched.
+func (c *CachingClient) writeSnapshots() {
+	defer close(c.snapshotDone)
+	for {
+		select {
+		case <-c.snapshotDirty:
+			// Wait for more changes, unless the client is being closed
+			_ = sleep(c.background, c.snapshotDelay)
+		case <-c.background.Done():
+			select {
+			case <-c.snapshotDirty:
+			default:
+				return
+			}
+		}
+
+		err := c.SaveSnapshot()
+		c.mu.Lock()
+		c.snapshotErr = err
+		c.mu.Unlock()
+
+		if c.background.Err() != nil {
+			return
+		}
+	}
+}
+
+// restoreSnapshot adds the entries of the snapshot file to the cache. A snapshot that cannot be read is ignored.
+func (c *CachingClient) restoreSnapshot() {
+	ciphertext, err := os.ReadFile(c.snapshotPath)
+	if err != nil {
+		return
+	}
+	plaintext, err := openSnapshot(c.snapshotKey, ciphertext)
+	if err != nil {
+		return
+	}
+	var entries []snapshotEntry
+	if err := json.Unmarshal(plaintext, &entries); err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, e := range entries {
+		if (e.Vault == nil) == (e.Item == nil) {
+			continue
+		}
+		entry := &cacheEntry{
+			key:       e.Key,
+			vault:     e.Vault,
+			item:      e.Item,
+			vaultUUID: e.VaultUUID,
+			fetched:   e.Fetched,
+			ttl:       e.TTL,
+		}
+		if c.retainable(entry) {
+			c.putLocked(entry)
+		}
+	}
+}
+
+func sealSnapshot(key []byte, plaintext []byte) ([]byte, error) {
+	gcm, err := snapshotCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func openSnapshot(key []byte, ciphertext []byte) ([]byte, error) {
+	gcm, err := snapshotCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("snapshot is too short")
+	}
+	nonce, sealed := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
+
+func snapshotCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,457 @@
+package connect
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+// countingMock serves items and vaults and counts the requests made per method and path.
+type countingMock struct {
+	mu       sync.Mutex
+	requests map[string]int
+	dofunc   func(req *http.Request) (*http.Response, error)
+}
+
+func newCountingMock(dofunc func(req *http.Request) (*http.Response, error)) *countingMock {
+	return &countingMock{requests: map[string]int{}, dofunc: dofunc}
+}
+
+func (m *countingMock) Do(req *http.Request) (*http.Response, error) {
+	m.mu.Lock()
+	m.requests[req.Method+" "+req.URL.Path]++
+	dofunc := m.dofunc
+	m.mu.Unlock()
+	return (&mockClient{Dofunc: dofunc}).Do(req)
+}
+
+func (m *countingMock) setDofunc(dofunc func(req *http.Request) (*http.Response, error)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dofunc = dofunc
+}
+
+func (m *countingMock) count(method string, path string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.requests[method+" "+path]
+}
+
+func (m *countingMock) total() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	total := 0
+	for _, count := range m.requests {
+		total += count
+	}
+	return total
+}
+
+func serveVaultsAndItems(req *http.Request) (*http.Response, error) {
+	switch {
+	case req.Method == http.MethodPut:
+		return updateItem(req)
+	case req.Method == http.MethodDelete:
+		return deleteItem(req)
+	case req.URL.Path == "/v1/vaults":
+		return listVaults(req)
+	default:
+		return listItemsOrGetItem(req)
+	}
+}
+
+func newTestCachingClient(t *testing.T, mock *countingMock, opts ...CacheOption) *CachingClient {
+	t.Helper()
+	client, err := NewCachingClient(NewClient(validHost, validToken, WithHTTPClient(mock)), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestCachingClientGetItem(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	client := newTestCachingClient(t, mock)
+
+	for i := 0; i < 3; i++ {
+		item, err := client.GetItem(testItemUUID, testVaultUUID)
+		assert.Nil(t, err)
+		assert.Equal(t, "test-item", item.Title)
+	}
+
+	assert.Equal(t, 1, mock.total())
+}
+
+func TestCachingClientResolvesVaultTitleOnce(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	client := newTestCachingClient(t, mock, WithItemTTL(0))
+
+	for i := 0; i < 3; i++ {
+		_, err := client.GetItemByUUID(testItemUUID, "Test vault")
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, 1, mock.count(http.MethodGet, "/v1/vaults"))
+	assert.Equal(t, 3, mock.count(http.MethodGet, "/v1/vaults/"+testID+"/items/"+testItemUUID))
+}
+
+func TestCachingClientReturnsCopies(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	client := newTestCachingClient(t, mock)
+
+	item, err := client.GetItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+	item.Fields[0].Value = "modified"
+
+	cached, err := client.GetItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+	assert.Equal(t, "wendy", cached.Fields[0].Value)
+}
+
+func TestCachingClientExpiry(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	client := newTestCachingClient(t, mock, WithItemTTL(time.Minute))
+	now := time.Now()
+	client.now = func() time.Time { return now }
+
+	_, err := client.GetItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = client.GetItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, mock.total())
+}
+
+func TestCachingClientMaxEntries(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	client := newTestCachingClient(t, mock, WithMaxCacheEntries(2))
+
+	_, err := client.GetItemByTitle("test-item", testVaultUUID)
+	assert.Nil(t, err)
+	_, err = client.GetVaultByTitle("Test vault")
+	assert.Nil(t, err)
+
+	assert.LessOrEqual(t, client.lru.Len(), 2)
+	assert.Len(t, client.entries, client.lru.Len())
+}
+
+func TestCachingClientInvalidatesOnUpdate(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	client := newTestCachingClient(t, mock)
+
+	item, err := client.GetItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	_, err = client.UpdateItem(item, testVaultUUID)
+	assert.Nil(t, err)
+
+	_, err = client.GetItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, mock.count(http.MethodGet, "/v1/vaults/"+testVaultUUID+"/items/"+testItemUUID))
+}
+
+func TestCachingClientInvalidatesOnDelete(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	client := newTestCachingClient(t, mock)
+
+	_, err := client.GetItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	err = client.DeleteItemByID(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	_, err = client.GetItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, mock.count(http.MethodGet, "/v1/vaults/"+testVaultUUID+"/items/"+testItemUUID))
+}
+
+func TestCachingClientUpdateItemDiffNilItem(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	client := newTestCachingClient(t, mock)
+
+	_, err := client.UpdateItemDiff(nil, generateItem(testVaultUUID), testVaultUUID)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, mock.total())
+}
+
+func TestCachingClientInvalidate(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	client := newTestCachingClient(t, mock)
+
+	_, err := client.GetItem(testItemUUID, "Test vault")
+	assert.Nil(t, err)
+
+	client.Invalidate("Test vault", "test-item")
+
+	_, err = client.GetItem(testItemUUID, "Test vault")
+	assert.Nil(t, err)
+
+	assert.Equal(t, 2, mock.count(http.MethodGet, "/v1/vaults/"+testID+"/items/"+testItemUUID))
+	assert.Equal(t, 1, mock.count(http.MethodGet, "/v1/vaults"))
+}
+
+func TestCachingClientLoadStruct(t *testing.T) {
+	type testConfig struct {
+		Username string `opitem:"test-item" opfield:"username"`
+		Password string `opitem:"test-item" opsection:"section" opfield:"password"`
+	}
+	mock := newCountingMock(listItemsOrGetItem)
+	client := newTestCachingClient(t, mock)
+
+	for i := 0; i < 2; i++ {
+		c := testConfig{}
+		err := client.LoadStruct(&c)
+		assert.Nil(t, err)
+		assert.Equal(t, "wendy", c.Username)
+		assert.Equal(t, "appleseed", c.Password)
+	}
+
+	assert.Equal(t, 2, mock.total(), "expected one request to list the items and one to fetch the item")
+}
+
+func connectUnreachable(req *http.Request) (*http.Response, error) {
+	return nil, &url.Error{Op: req.Method, URL: req.URL.String(), Err: errors.New("connection refused")}
+}
+
+// testClock is a clock for the CachingClient that can be advanced by tests.
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestCachingClientStaleWhileRevalidate(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	clock := &testClock{now: time.Now()}
+	client := newTestCachingClient(t, mock, WithItemTTL(time.Minute), WithStaleWhileRevalidate(time.Minute))
+	client.now = clock.Now
+	defer client.Close()
+
+	_, err := client.GetItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	clock.Advance(90 * time.Second)
+	cached, err := client.GetCachedItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+	assert.True(t, cached.Stale)
+	assert.Equal(t, 90*time.Second, cached.Age)
+
+	assert.Eventually(t, func() bool {
+		cached, err := client.GetCachedItem(testItemUUID, testVaultUUID)
+		return err == nil && !cached.Stale
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 2, mock.total())
+}
+
+func TestCachingClientOfflineFallback(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	clock := &testClock{now: time.Now()}
+	client := newTestCachingClient(t, mock, WithItemTTL(time.Minute), WithOfflineFallback(time.Hour))
+	client.now = clock.Now
+	client.recoveryBackoff = time.Millisecond
+	defer client.Close()
+
+	_, err := client.GetItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	mock.setDofunc(connectUnreachable)
+	clock.Advance(10 * time.Minute)
+
+	cached, err := client.GetCachedItem(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+	assert.True(t, cached.Stale)
+	assert.Equal(t, 10*time.Minute, cached.Age)
+	assert.Equal(t, "wendy", cached.Fields[0].Value)
+
+	// The item is refreshed in the background once Connect is reachable again
+	mock.setDofunc(serveVaultsAndItems)
+	assert.Eventually(t, func() bool {
+		cached, err := client.GetCachedItem(testItemUUID, testVaultUUID)
+		return err == nil && !cached.Stale
+	}, time.Second, time.Millisecond)
+}
+
+func TestCachingClientOfflineFallbackServerError(t *testing.T) {
+	mock := newCountingMock(serveVaultsAndItems)
+	clock := &testClock{now: time.Now()}
+	client := newTestCachingClient(t, mock, WithItemTTL(time.Minute), WithOfflineFallback(0))
+	client.now = clock.Now
+	defer client.Close()
+
+	_, err := client.GetItemByUUID(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	mock.setDofunc(respondError(apiError(http.StatusServiceUnavailable, "unavailable")))
+	clock.Advance(24 * time.Hour)
+
+	item, err := client.GetItemByUUID(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+	assert.NotNil(t, item)
+}
+
+func TestCachingClientOfflineFallbackLimits(t *testing.T) {
+	cases := map[string]struct {
+		failure func(req *http.Request) (*http.Response, error)
+		elapsed time.Duration
+	}{
+		"not found": {
+			failure: respondError(apiError(http.StatusNotFound, "item not found")),
+			elapsed: 2 * time.Minute,
+		},
+		"too old": {
+			failure: connectUnreachable,
+			elapsed: 2 * time.Hour,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mock := newCountingMock(serveVaultsAndItems)
+			clock := &testClock{now: time.Now()}
+			client := newTestCachingClient(t, mock, WithItemTTL(time.Minute), WithOfflineFallback(time.Hour))
+			client.now = clock.Now
+			defer client.Close()
+
+			_, err := client.GetItemByUUID(testItemUUID, testVaultUUID)
+			assert.Nil(t, err)
+
+			mock.setDofunc(tc.failure)
+			clock.Advance(tc.elapsed)
+
+			_, err = client.GetItemByUUID(testItemUUID, testVaultUUID)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCachingClientSnapshot(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot")
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	mock := newCountingMock(serveVaultsAndItems)
+	client := newTestCachingClient(t, mock, WithOfflineFallback(0), WithSnapshotFile(path, key))
+	_, err := client.GetItem(testItemUUID, "Test vault")
+	assert.Nil(t, err)
+	assert.Nil(t, client.SaveSnapshot())
+	client.Close()
+
+	content, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(content), "appleseed")
+
+	// A client started while Connect is unreachable serves the items from the snapshot
+	offline := newCountingMock(connectUnreachable)
+	restored := newTestCachingClient(t, offline, WithOfflineFallback(0), WithSnapshotFile(path, key))
+	restored.now = func() time.Time { return time.Now().Add(time.Hour) }
+	defer restored.Close()
+
+	item, err := restored.GetItem(testItemUUID, "Test vault")
+	assert.Nil(t, err)
+	if assert.NotNil(t, item) {
+		assert.Equal(t, "appleseed", item.Fields[1].Value)
+	}
+
+	// A snapshot encrypted with another key is ignored
+	wrongKey := newTestCachingClient(t, offline, WithOfflineFallback(0), WithSnapshotFile(path, []byte("fedcba9876543210fedcba9876543210")))
+	defer wrongKey.Close()
+	_, err = wrongKey.GetItem(testItemUUID, "Test vault")
+	assert.Error(t, err)
+}
+
+func TestCachingClientSnapshotWrittenInBackground(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot")
+	key := []byte("0123456789abcdef")
+
+	client := newTestCachingClient(t, newCountingMock(serveVaultsAndItems), WithOfflineFallback(0), WithSnapshotFile(path, key))
+	_, err := client.GetItem(testItemUUID, "Test vault")
+	assert.Nil(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "the snapshot should not be written by the caller")
+
+	client.Close()
+	assert.Nil(t, client.SnapshotError())
+
+	restored := newTestCachingClient(t, newCountingMock(connectUnreachable), WithOfflineFallback(0), WithSnapshotFile(path, key))
+	defer restored.Close()
+	_, err = restored.GetItem(testItemUUID, "Test vault")
+	assert.Nil(t, err)
+}
+
+func TestCachingClientSnapshotForgetsDeletedItems(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot")
+	key := []byte("0123456789abcdef")
+
+	client := newTestCachingClient(t, newCountingMock(serveVaultsAndItems), WithOfflineFallback(0), WithSnapshotFile(path, key))
+	_, err := client.GetItem(testItemUUID, "Test vault")
+	assert.Nil(t, err)
+	assert.Nil(t, client.SaveSnapshot())
+
+	assert.Nil(t, client.DeleteItemByID(testItemUUID, "Test vault"))
+	client.Close()
+	assert.Nil(t, client.SnapshotError())
+
+	restored := newTestCachingClient(t, newCountingMock(connectUnreachable), WithOfflineFallback(0), WithSnapshotFile(path, key))
+	defer restored.Close()
+	_, err = restored.GetItem(testItemUUID, "Test vault")
+	assert.Error(t, err, "a deleted item should not be restored from the snapshot")
+}
+
+func TestCachingClientSnapshotError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "missing", "snapshot")
+
+	client := newTestCachingClient(t, newCountingMock(serveVaultsAndItems), WithSnapshotFile(path, []byte("0123456789abcdef")))
+	_, err := client.GetItem(testItemUUID, "Test vault")
+	assert.Nil(t, err, "a snapshot that cannot be written should not fail the request")
+
+	client.Close()
+	assert.Error(t, client.SnapshotError())
+}
+
+func TestCachingClientInvalidSnapshotKey(t *testing.T) {
+	wrapped := NewClient(validHost, validToken, WithHTTPClient(newCountingMock(serveVaultsAndItems)))
+	path := filepath.Join(t.TempDir(), "snapshot")
+
+	client, err := NewCachingClient(wrapped, WithSnapshotFile(path, []byte("short")))
+	assert.Nil(t, client)
+	assert.ErrorContains(t, err, "invalid snapshot key")
+}
+
+func TestCopyItem(t *testing.T) {
+	item := generateItem(testVaultUUID)
+	item.Files = []*onepassword.File{generateFile()}
+
+	copied := copyItem(item)
+
+	assert.Equal(t, item, copied)
+	copied.Fields[1].Section.Label = "changed"
+	copied.URLs[0].URL = "changed"
+	copied.Files[0].Name = "changed"
+	assert.Equal(t, generateItem(testVaultUUID).Fields, item.Fields)
+	assert.Equal(t, generateItem(testVaultUUID).URLs, item.URLs)
+	assert.Equal(t, "testfile.txt", item.Files[0].Name)
+}
@@ -0,0 +1,177 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+const (
+	minVersionProbeBackoff = time.Second
+	maxVersionProbeBackoff = time.Minute
+	// versionProbeTimeout limits how long the request for the server version may take, since it is not canceled
+	// with the context of the caller that started it.
+	versionProbeTimeout = 5 * time.Second
+)
+
+// Capability is a feature of Connect that is only available from a certain version of the Connect server on.
+type Capability string
+
+const (
+	// CapFiles is the support for listing and downloading the files attached to items.
+	CapFiles Capability = "files"
+	// CapPatch is the support for patching items with JSON Patch operations, used by PatchItem and UpdateItemDiff.
+	CapPatch Capability = "patch"
+)
+
+// capabilityVersions holds the first version of Connect that supports each capability.
+var capabilityVersions = map[Capability]version{
+	CapFiles: {1, 3, 0},
+	CapPatch: {1, 2, 0},
+}
+
+// Supports Check whether the Connect server supports the given capability
+func (rs *restClient) Supports(capability Capability) (bool, error) {
+	return rs.SupportsWithContext(context.Background(), capability)
+}
+
+// SupportsWithContext Check whether the Connect server supports the given capability. The version of the server is
+// requested the first time a capability is checked and reused for all following checks.
+func (rs *restClient) SupportsWithContext(ctx context.Context, capability Capability) (bool, error) {
+	if _, found := capabilityVersions[capability]; !found {
+		return false, fmt.Errorf("unknown capability %q", capability)
+	}
+	detected, err := rs.detectServerVersion(ctx)
+	if err != nil {
+		return false, err
+	}
+	return checkCapability(capability, detected) == nil, nil
+}
+
+// requireCapability returns an *UnsupportedServerVersionError if the Connect server does not support the given
+// capability. If the version of the server cannot be determined, the error is logged and the capability is
+// assumed to be supported, so that the caller's request reports the actual problem.
+func (rs *restClient) requireCapability(ctx context.Context, capability Capability) error {
+	detected, err := rs.detectServerVersion(ctx)
+	if err != nil {
+		if rs.logger != nil {
+			rs.logger.LogAttrs(ctx, slog.LevelWarn, "connect server version unknown",
+				slog.String("capability", string(capability)),
+				slog.String("error", err.Error()),
+			)
+		}
+		return nil
+	}
+	return checkCapability(capability, detected)
+}
+
+// checkCapability returns an *UnsupportedServerVersionError if the detected version does not support the given
+// capability. A server that did not report its version may be older than the version it is assumed to be, so it
+// does not support any capability.
+func checkCapability(capability Capability, detected serverVersion) error {
+	minimum := capabilityVersions[capability]
+	if !detected.orEarlier && detected.IsGreaterOrEqualThan(minimum) {
+		return nil
+	}
+	return &UnsupportedServerVersionError{
+		Capability: capability,
+		Required:   serverVersion{version: minimum}.exported(),
+		Detected:   detected.exported(),
+	}
+}
+
+// versionProbe is a request for the version of the Connect server, shared by all callers that need the version
+// while it is in flight.
+type versionProbe struct {
+	done     chan struct{}
+	detected serverVersion
+	err      error
+}
+
+// detectServerVersion returns the version of the Connect server, requesting it if it is not known yet. Concurrent
+// callers share a single request, and each stops waiting for it when its own ctx is done, while the request itself
+// is limited by versionProbeTimeout. If the request fails, the error is returned without a new request until a
+// backoff has passed, so that a degraded Connect server is not probed by every call.
+func (rs *restClient) detectServerVersion(ctx context.Context) (serverVersion, error) {
+	rs.detectedVersionMu.Lock()
+	if rs.detectedVersion != nil {
+		detected := *rs.detectedVersion
+		rs.detectedVersionMu.Unlock()
+		return detected, nil
+	}
+	if rs.versionProbeErr != nil && time.Now().Before(rs.versionRetryAt) {
+		err := rs.versionProbeErr
+		rs.detectedVersionMu.Unlock()
+		return serverVersion{}, err
+	}
+	probe := rs.versionProbe
+	if probe == nil {
+		probe = &versionProbe{done: make(chan struct{})}
+		rs.versionProbe = probe
+		// The probe is shared, so it must not be canceled if the caller that started it gives up
+		probeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), versionProbeTimeout)
+		go func() {
+			defer cancel()
+			rs.runVersionProbe(probeCtx, probe)
+		}()
+	}
+	rs.detectedVersionMu.Unlock()
+
+	select {
+	case <-probe.done:
+		return probe.detected, probe.err
+	case <-ctx.Done():
+		return serverVersion{}, ctx.Err()
+	}
+}
+
+func (rs *restClient) runVersionProbe(ctx context.Context, probe *versionProbe) {
+	detected, err := rs.requestServerVersion(ctx)
+
+	rs.detectedVersionMu.Lock()
+	rs.versionProbe = nil
+	if err != nil {
+		if rs.versionProbeBackoff *= 2; rs.versionProbeBackoff < minVersionProbeBackoff {
+			rs.versionProbeBackoff = minVersionProbeBackoff
+		} else if rs.versionProbeBackoff > maxVersionProbeBackoff {
+			rs.versionProbeBackoff = maxVersionProbeBackoff
+		}
+		rs.versionProbeErr = err
+		rs.versionRetryAt = time.Now().Add(rs.versionProbeBackoff)
+	} else {
+		rs.detectedVersion = &detected
+		rs.versionProbeErr = nil
+		rs.versionProbeBackoff = 0
+	}
+	rs.detectedVersionMu.Unlock()
+
+	probe.detected, probe.err = detected, err
+	close(probe.done)
+}
+
+// requestServerVersion reads the version of the Connect server from the response to a heartbeat request.
+func (rs *restClient) requestServerVersion(ctx context.Context) (serverVersion, error) {
+	span, ctx := rs.startSpan(ctx, "ServerVersion")
+	defer span.End()
+
+	request, err := rs.buildRequest(ctx, http.MethodGet, "/heartbeat", http.NoBody, span)
+	if err != nil {
+		return serverVersion{}, err
+	}
+
+	response, err := rs.do(request)
+	if err != nil {
+		return serverVersion{}, err
+	}
+	if _, err := readResponseBody(response, http.StatusOK); err != nil {
+		return serverVersion{}, err
+	}
+
+	detected, err := getServerVersion(response)
+	if err != nil {
+		return serverVersion{}, fmt.Errorf("parsing server version %q: %w", response.Header.Get(VersionHeaderKey), err)
+	}
+	return detected, nil
+}
@@ -0,0 +1,197 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// serveVersion responds to every request with an empty body and the given server version.
+func serveVersion(v string) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set(VersionHeaderKey, v)
+		return &http.Response{
+			Status:     http.StatusText(http.StatusOK),
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("[]")),
+			Header:     header,
+		}, nil
+	}
+}
+
+func newCapabilityTestClient(mock *countingMock) *restClient {
+	return &restClient{
+		URL:        validHost,
+		Token:      validToken,
+		userAgent:  testUserAgent,
+		tracer:     noop.NewTracerProvider().Tracer(tracerName),
+		propagator: propagation.TraceContext{},
+		client:     mock,
+	}
+}
+
+func TestSupports(t *testing.T) {
+	mock := newCountingMock(serveVersion("1.2.0"))
+	client := newCapabilityTestClient(mock)
+
+	supported, err := client.Supports(CapPatch)
+	assert.Nil(t, err)
+	assert.True(t, supported)
+
+	supported, err = client.Supports(CapFiles)
+	assert.Nil(t, err)
+	assert.False(t, supported)
+
+	assert.Equal(t, 1, mock.count(http.MethodGet, "/heartbeat"))
+}
+
+func TestSupportsUnknownCapability(t *testing.T) {
+	mock := newCountingMock(serveVersion("1.2.0"))
+	client := newCapabilityTestClient(mock)
+
+	_, err := client.Supports(Capability("teleportation"))
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, mock.total())
+}
+
+func TestSupportsProbeFailed(t *testing.T) {
+	mock := newCountingMock(func(req *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("connection refused")
+	})
+	client := newCapabilityTestClient(mock)
+
+	_, err := client.Supports(CapFiles)
+	assert.NotNil(t, err)
+
+	// A failed probe is not repeated until the backoff has passed
+	mock.setDofunc(serveVersion("1.3.0"))
+	_, err = client.Supports(CapFiles)
+	assert.ErrorContains(t, err, "connection refused")
+	assert.Equal(t, 1, mock.count(http.MethodGet, "/heartbeat"))
+
+	client.versionRetryAt = time.Now()
+	supported, err := client.Supports(CapFiles)
+	assert.Nil(t, err)
+	assert.True(t, supported)
+	assert.Equal(t, 2, mock.count(http.MethodGet, "/heartbeat"))
+}
+
+func TestSupportsSharesProbe(t *testing.T) {
+	release := make(chan struct{})
+	mock := newCountingMock(func(req *http.Request) (*http.Response, error) {
+		<-release
+		return serveVersion("1.3.0")(req)
+	})
+	client := newCapabilityTestClient(mock)
+
+	// A caller does not wait for the probe past its own deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.SupportsWithContext(ctx, CapFiles)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			supported, err := client.Supports(CapFiles)
+			assert.Nil(t, err)
+			assert.True(t, supported)
+		}()
+	}
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, 1, mock.count(http.MethodGet, "/heartbeat"))
+}
+
+func TestSupportsUnreportedServerVersion(t *testing.T) {
+	// mockClient adds a version header to every response, which a server before v1.3.0 does not send
+	client := newCapabilityTestClient(nil)
+	client.client = &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader("{}")),
+			Header:     http.Header{},
+		}, nil
+	})}
+
+	// A server without version header may be older than v1.2.0
+	supported, err := client.Supports(CapPatch)
+	assert.Nil(t, err)
+	assert.False(t, supported)
+
+	_, err = client.PatchItem(testItemUUID, testVaultUUID, nil)
+	assert.ErrorIs(t, err, ErrUnsupportedServerVersion)
+}
+
+func TestVersionProbeHasDeadline(t *testing.T) {
+	var hasDeadline bool
+	mock := newCountingMock(func(req *http.Request) (*http.Response, error) {
+		_, hasDeadline = req.Context().Deadline()
+		return serveVersion("1.3.0")(req)
+	})
+	client := newCapabilityTestClient(mock)
+
+	_, err := client.Supports(CapFiles)
+
+	assert.Nil(t, err)
+	assert.True(t, hasDeadline)
+}
+
+func TestUnsupportedServerVersionUpFront(t *testing.T) {
+	mock := newCountingMock(serveVersion("1.2.0"))
+	client := newCapabilityTestClient(mock)
+
+	_, err := client.GetFiles(testItemUUID, testVaultUUID)
+
+	assert.ErrorIs(t, err, ErrUnsupportedServerVersion)
+	var unsupported *UnsupportedServerVersionError
+	if assert.ErrorAs(t, err, &unsupported) {
+		assert.Equal(t, &UnsupportedServerVersionError{
+			Capability: CapFiles,
+			Required:   ServerVersion{Major: 1, Minor: 3},
+			Detected:   ServerVersion{Major: 1, Minor: 2},
+		}, unsupported)
+	}
+	assert.Equal(t, 1, mock.total())
+}
+
+func TestUnknownServerVersionDoesNotBlockRequests(t *testing.T) {
+	mock := newCountingMock(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/heartbeat" {
+			return nil, fmt.Errorf("connection refused")
+		}
+		return serveVersion("1.3.0")(req)
+	})
+	logger, output := newTestLogger(slog.LevelWarn)
+	client := newCapabilityTestClient(mock)
+	client.logger = logger
+
+	files, err := client.GetFiles(testItemUUID, testVaultUUID)
+
+	assert.Nil(t, err)
+	assert.Empty(t, files)
+	assert.Equal(t, 1, mock.count(http.MethodGet, fmt.Sprintf("/v1/vaults/%s/items/%s/files", testVaultUUID, testItemUUID)))
+
+	// The reason the version is unknown is logged
+	records := logRecords(t, output)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, "WARN", records[0]["level"])
+		assert.Equal(t, string(CapFiles), records[0]["capability"])
+		assert.Contains(t, records[0]["error"], "connection refused")
+	}
+}
@@ -2,63 +2,125 @@ package connect
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
-	"reflect"
 	"regexp"
+	"strconv"
+	"sync"
+	"time"
 
-	"github.com/opentracing/opentracing-go"
-	"github.com/opentracing/opentracing-go/ext"
-	jaegerClientConfig "github.com/uber/jaeger-client-go/config"
-	"github.com/uber/jaeger-client-go/zipkin"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/1Password/connect-sdk-go/filter"
 	"github.com/1Password/connect-sdk-go/onepassword"
 )
 
 const (
 	defaultUserAgent = "connect-sdk-go/%s"
+
+	// maxModifyAttempts is the number of times ModifyItem reads and updates an item before giving up on conflicts
+	maxModifyAttempts = 5
+	// defaultConcurrency is the number of items fetched at the same time by bulk operations such as GetItemsFull
+	defaultConcurrency = 4
 )
 
 var (
-	vaultUUIDError = fmt.Errorf("malformed vault uuid provided")
-	itemUUIDError  = fmt.Errorf("malformed item uuid provided")
-	fileUUIDError  = fmt.Errorf("malformed file uuid provided")
+	vaultUUIDError = &classifiedError{class: ErrInvalidID, err: errors.New("malformed vault uuid provided")}
+	itemUUIDError  = &classifiedError{class: ErrInvalidID, err: errors.New("malformed item uuid provided")}
+	fileUUIDError  = &classifiedError{class: ErrInvalidID, err: errors.New("malformed file uuid provided")}
 )
 
-// Client Represents an available 1Password Connect API to connect to
+// Client Represents an available 1Password Connect API to connect to.
+// Every method has a WithContext counterpart that accepts a context.Context which is used for all requests
+// made to the Connect API, including the lookups needed to resolve vault and item titles.
 type Client interface {
 	GetVaults() ([]onepassword.Vault, error)
+	GetVaultsWithContext(ctx context.Context, opts ...ListOption) ([]onepassword.Vault, error)
 	GetVault(uuid string) (*onepassword.Vault, error)
+	GetVaultWithContext(ctx context.Context, uuid string) (*onepassword.Vault, error)
 	GetVaultByUUID(uuid string) (*onepassword.Vault, error)
+	GetVaultByUUIDWithContext(ctx context.Context, uuid string) (*onepassword.Vault, error)
 	GetVaultByTitle(title string) (*onepassword.Vault, error)
+	GetVaultByTitleWithContext(ctx context.Context, title string) (*onepassword.Vault, error)
 	GetVaultsByTitle(uuid string) ([]onepassword.Vault, error)
+	GetVaultsByTitleWithContext(ctx context.Context, uuid string) ([]onepassword.Vault, error)
 	GetItems(vaultQuery string) ([]onepassword.Item, error)
+	GetItemsWithContext(ctx context.Context, vaultQuery string, opts ...ListOption) ([]onepassword.Item, error)
+	GetItemSummaries(vaultQuery string) ([]onepassword.ItemSummary, error)
+	GetItemSummariesWithContext(ctx context.Context, vaultQuery string, opts ...ListOption) ([]onepassword.ItemSummary, error)
 	GetItem(itemQuery, vaultQuery string) (*onepassword.Item, error)
+	GetItemWithContext(ctx context.Context, itemQuery, vaultQuery string) (*onepassword.Item, error)
 	GetItemByUUID(uuid string, vaultQuery string) (*onepassword.Item, error)
+	GetItemByUUIDWithContext(ctx context.Context, uuid string, vaultQuery string) (*onepassword.Item, error)
 	GetItemByTitle(title string, vaultQuery string) (*onepassword.Item, error)
+	GetItemByTitleWithContext(ctx context.Context, title string, vaultQuery string) (*onepassword.Item, error)
 	GetItemsByTitle(title string, vaultQuery string) ([]onepassword.Item, error)
+	GetItemsByTitleWithContext(ctx context.Context, title string, vaultQuery string) ([]onepassword.Item, error)
+	GetItemsByID(vaultQuery string, ids []string) ([]onepassword.Item, error)
+	GetItemsByIDWithContext(ctx context.Context, vaultQuery string, ids []string) ([]onepassword.Item, error)
+	GetItemsFull(vaultQuery string) ([]onepassword.Item, error)
+	GetItemsFullWithContext(ctx context.Context, vaultQuery string) ([]onepassword.Item, error)
 	CreateItem(item *onepassword.Item, vaultQuery string) (*onepassword.Item, error)
+	CreateItemWithContext(ctx context.Context, item *onepassword.Item, vaultQuery string) (*onepassword.Item, error)
 	UpdateItem(item *onepassword.Item, vaultQuery string) (*onepassword.Item, error)
+	UpdateItemWithContext(ctx context.Context, item *onepassword.Item, vaultQuery string) (*onepassword.Item, error)
+	PatchItem(itemQuery string, vaultQuery string, ops []onepassword.PatchOperation) (*onepassword.Item, error)
+	PatchItemWithContext(ctx context.Context, itemQuery string, vaultQuery string, ops []onepassword.PatchOperation) (*onepassword.Item, error)
+	UpdateItemDiff(original *onepassword.Item, modified *onepassword.Item, vaultQuery string) (*onepassword.Item, error)
+	UpdateItemDiffWithContext(ctx context.Context, original *onepassword.Item, modified *onepassword.Item, vaultQuery string) (*onepassword.Item, error)
+	UpdateItemChecked(item *onepassword.Item, vaultQuery string) (*onepassword.Item, error)
+	UpdateItemCheckedWithContext(ctx context.Context, item *onepassword.Item, vaultQuery string) (*onepassword.Item, error)
+	ModifyItem(itemQuery string, vaultQuery string, modify func(*onepassword.Item) error) (*onepassword.Item, error)
+	ModifyItemWithContext(ctx context.Context, itemQuery string, vaultQuery string, modify func(*onepassword.Item) error) (*onepassword.Item, error)
 	DeleteItem(item *onepassword.Item, vaultQuery string) error
+	DeleteItemWithContext(ctx context.Context, item *onepassword.Item, vaultQuery string) error
 	DeleteItemByID(itemUUID string, vaultQuery string) error
+	DeleteItemByIDWithContext(ctx context.Context, itemUUID string, vaultQuery string) error
 	DeleteItemByTitle(title string, vaultQuery string) error
+	DeleteItemByTitleWithContext(ctx context.Context, title string, vaultQuery string) error
 	GetFiles(itemQuery string, vaultQuery string) ([]onepassword.File, error)
+	GetFilesWithContext(ctx context.Context, itemQuery string, vaultQuery string) ([]onepassword.File, error)
 	GetFile(uuid string, itemQuery string, vaultQuery string) (*onepassword.File, error)
+	GetFileWithContext(ctx context.Context, uuid string, itemQuery string, vaultQuery string) (*onepassword.File, error)
 	GetFileContent(file *onepassword.File) ([]byte, error)
+	GetFileContentWithContext(ctx context.Context, file *onepassword.File) ([]byte, error)
 	DownloadFile(file *onepassword.File, targetDirectory string, overwrite bool) (string, error)
+	DownloadFileWithContext(ctx context.Context, file *onepassword.File, targetDirectory string, overwrite bool) (string, error)
+	Heartbeat() error
+	HeartbeatWithContext(ctx context.Context) error
+	Health() (*onepassword.ServerHealth, error)
+	HealthWithContext(ctx context.Context) (*onepassword.ServerHealth, error)
+	ServerInfo() (*ServerInfo, error)
+	ServerInfoWithContext(ctx context.Context) (*ServerInfo, error)
+	Supports(capability Capability) (bool, error)
+	SupportsWithContext(ctx context.Context, capability Capability) (bool, error)
+	GetAPIActivity(limit int, offset int) ([]onepassword.APIRequest, error)
+	GetAPIActivityWithContext(ctx context.Context, limit int, offset int) ([]onepassword.APIRequest, error)
 	LoadStructFromItemByUUID(config interface{}, itemUUID string, vaultQuery string) error
+	LoadStructFromItemByUUIDWithContext(ctx context.Context, config interface{}, itemUUID string, vaultQuery string) error
 	LoadStructFromItemByTitle(config interface{}, itemTitle string, vaultQuery string) error
+	LoadStructFromItemByTitleWithContext(ctx context.Context, config interface{}, itemTitle string, vaultQuery string) error
 	LoadStructFromItem(config interface{}, itemQuery string, vaultQuery string) error
+	LoadStructFromItemWithContext(ctx context.Context, config interface{}, itemQuery string, vaultQuery string) error
 	LoadStruct(config interface{}) error
+	LoadStructWithContext(ctx context.Context, config interface{}) error
+	ResolveReference(ref string) (string, error)
+	ResolveReferenceWithContext(ctx context.Context, ref string) (string, error)
 }
 
-type httpClient interface {
+// HTTPClient is the interface of the HTTP client used to send requests to Connect.
+// It is satisfied by *http.Client and can be set with the WithHTTPClient option.
+type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
@@ -69,7 +131,7 @@ const (
 
 // NewClientFromEnvironment Returns a Secret Service client assuming that your
 // jwt is set in the OP_TOKEN environment variable
-func NewClientFromEnvironment() (Client, error) {
+func NewClientFromEnvironment(opts ...ClientOption) (Client, error) {
 	host, found := os.LookupEnv(envHostVariable)
 	if !found {
 		return nil, fmt.Errorf("There is no hostname available in the %q variable", envHostVariable)
@@ -80,99 +142,128 @@ func NewClientFromEnvironment() (Client, error) {
 		return nil, fmt.Errorf("There is no token available in the %q variable", envTokenVariable)
 	}
 
-	return NewClient(host, token), nil
-}
-
-// NewClient Returns a Secret Service client for a given url and jwt
-func NewClient(url string, token string) Client {
-	return NewClientWithUserAgent(url, token, fmt.Sprintf(defaultUserAgent, SDKVersion))
+	return NewClient(host, token, opts...), nil
 }
 
-// NewClientWithUserAgent Returns a Secret Service client for a given url and jwt and identifies with userAgent
-func NewClientWithUserAgent(url string, token string, userAgent string) Client {
-	if !opentracing.IsGlobalTracerRegistered() {
-		cfg := jaegerClientConfig.Configuration{}
-		zipkinPropagator := zipkin.NewZipkinB3HTTPHeaderPropagator()
-		cfg.InitGlobalTracer(
-			userAgent,
-			jaegerClientConfig.Injector(opentracing.HTTPHeaders, zipkinPropagator),
-			jaegerClientConfig.Extractor(opentracing.HTTPHeaders, zipkinPropagator),
-			jaegerClientConfig.ZipkinSharedRPCSpan(true),
-		)
+// NewClient Returns a Secret Service client for a given url and jwt, configured with the provided options
+func NewClient(url string, token string, opts ...ClientOption) Client {
+	cfg := clientConfig{
+		userAgent: fmt.Sprintf(defaultUserAgent, SDKVersion),
+	}
+	for _, opt := range opts {
+		opt(&cfg)
 	}
 
+	tracer, propagator := cfg.buildTracer()
+
 	return &restClient{
 		URL:   url,
 		Token: token,
 
-		userAgent: userAgent,
-		tracer:    opentracing.GlobalTracer(),
+		userAgent: cfg.userAgent,
+		headers:   cfg.headers,
+
+		tracer:     tracer,
+		propagator: propagator,
+		logger:     cfg.logger,
+		metrics:    cfg.metrics,
+
+		client:      cfg.buildHTTPClient(),
+		retryPolicy: cfg.retryPolicy,
+		requests:    cfg.buildRequestGroup(),
+		concurrency: cfg.concurrency,
 
-		client: http.DefaultClient,
+		strictLoadStruct: cfg.strictLoadStruct,
 	}
 }
 
+// NewClientWithUserAgent Returns a Secret Service client for a given url and jwt and identifies with userAgent
+func NewClientWithUserAgent(url string, token string, userAgent string) Client {
+	return NewClient(url, token, WithUserAgent(userAgent))
+}
+
 type restClient struct {
-	URL       string
-	Token     string
-	userAgent string
-	tracer    opentracing.Tracer
-	client    httpClient
+	URL        string
+	Token      string
+	userAgent  string
+	headers    http.Header
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	logger     *slog.Logger
+	metrics    MetricsRecorder
+	client     HTTPClient
+
+	retryPolicy RetryPolicy
+	// requests deduplicates concurrent GET requests, if enabled with WithRequestCoalescing
+	requests *requestGroup
+	// concurrency is the maximum number of items fetched at the same time by bulk operations
+	concurrency int
+	// strictLoadStruct makes the LoadStruct methods fail for fields that have no value in the item
+	strictLoadStruct bool
+
+	detectedVersionMu sync.Mutex
+	// detectedVersion is the version of the Connect server, once it has been requested
+	detectedVersion *serverVersion
+	// versionProbe is the request for the server version in flight, if any
+	versionProbe *versionProbe
+	// versionProbeErr is the error of the last failed request for the server version, which is returned until
+	// versionRetryAt
+	versionProbeErr     error
+	versionRetryAt      time.Time
+	versionProbeBackoff time.Duration
 }
 
 // GetVaults Get a list of all available vaults
 func (rs *restClient) GetVaults() ([]onepassword.Vault, error) {
-	span := rs.tracer.StartSpan("GetVaults")
-	defer span.Finish()
-
-	vaultURL := fmt.Sprintf("/v1/vaults")
-	request, err := rs.buildRequest(http.MethodGet, vaultURL, http.NoBody, span)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := rs.client.Do(request)
-	if err != nil {
-		return nil, err
-	}
+	return rs.GetVaultsWithContext(context.Background())
+}
 
-	var vaults []onepassword.Vault
-	if err := parseResponse(response, http.StatusOK, &vaults); err != nil {
-		return nil, err
-	}
+// GetVaultsWithContext Get a list of all available vaults, or of those that match the filter set with WithFilter
+func (rs *restClient) GetVaultsWithContext(ctx context.Context, opts ...ListOption) ([]onepassword.Vault, error) {
+	span, ctx := rs.startSpan(ctx, "GetVaults")
+	defer span.End()
 
-	return vaults, nil
+	return rs.listVaults(ctx, newListConfig(opts).filter, span)
 }
 
 // GetVault Get a vault based on its name or ID
 func (rs *restClient) GetVault(vaultQuery string) (*onepassword.Vault, error) {
-	span := rs.tracer.StartSpan("GetVault")
-	defer span.Finish()
+	return rs.GetVaultWithContext(context.Background(), vaultQuery)
+}
+
+// GetVaultWithContext Get a vault based on its name or ID
+func (rs *restClient) GetVaultWithContext(ctx context.Context, vaultQuery string) (*onepassword.Vault, error) {
+	span, ctx := rs.startSpan(ctx, "GetVault")
+	defer span.End()
 
 	if vaultQuery == "" {
 		return nil, fmt.Errorf("Please provide either the vault name or its ID.")
 	}
 	if !isValidUUID(vaultQuery) {
-		return rs.GetVaultByTitle(vaultQuery)
+		return rs.GetVaultByTitleWithContext(ctx, vaultQuery)
 	}
-	return rs.GetVaultByUUID(vaultQuery)
+	return rs.GetVaultByUUIDWithContext(ctx, vaultQuery)
 }
 
 func (rs *restClient) GetVaultByUUID(uuid string) (*onepassword.Vault, error) {
+	return rs.GetVaultByUUIDWithContext(context.Background(), uuid)
+}
+
+func (rs *restClient) GetVaultByUUIDWithContext(ctx context.Context, uuid string) (*onepassword.Vault, error) {
 	if !isValidUUID(uuid) {
 		return nil, vaultUUIDError
 	}
 
-	span := rs.tracer.StartSpan("GetVaultByUUID")
-	defer span.Finish()
+	span, ctx := rs.startSpan(ctx, "GetVaultByUUID")
+	defer span.End()
 
 	vaultURL := fmt.Sprintf("/v1/vaults/%s", uuid)
-	request, err := rs.buildRequest(http.MethodGet, vaultURL, http.NoBody, span)
+	request, err := rs.buildRequest(ctx, http.MethodGet, vaultURL, http.NoBody, span)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := rs.client.Do(request)
+	response, err := rs.do(request)
 	if err != nil {
 		return nil, err
 	}
@@ -185,33 +276,51 @@ func (rs *restClient) GetVaultByUUID(uuid string) (*onepassword.Vault, error) {
 }
 
 func (rs *restClient) GetVaultByTitle(vaultName string) (*onepassword.Vault, error) {
-	span := rs.tracer.StartSpan("GetVaultByTitle")
-	defer span.Finish()
+	return rs.GetVaultByTitleWithContext(context.Background(), vaultName)
+}
+
+func (rs *restClient) GetVaultByTitleWithContext(ctx context.Context, vaultName string) (*onepassword.Vault, error) {
+	span, ctx := rs.startSpan(ctx, "GetVaultByTitle")
+	defer span.End()
 
-	vaults, err := rs.GetVaultsByTitle(vaultName)
+	vaults, err := rs.GetVaultsByTitleWithContext(ctx, vaultName)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(vaults) != 1 {
-		return nil, fmt.Errorf("Found %d vaults with title %q", len(vaults), vaultName)
+	switch len(vaults) {
+	case 0:
+		return nil, &classifiedError{class: ErrVaultNotFound, err: fmt.Errorf("Found 0 vaults with title %q", vaultName)}
+	case 1:
+		return &vaults[0], nil
 	}
-
-	return &vaults[0], nil
+	ids := make([]string, len(vaults))
+	for i, vault := range vaults {
+		ids[i] = vault.ID
+	}
+	return nil, &AmbiguousTitleError{Title: vaultName, IDs: ids}
 }
 
 func (rs *restClient) GetVaultsByTitle(title string) ([]onepassword.Vault, error) {
-	span := rs.tracer.StartSpan("GetVaultsByTitle")
-	defer span.Finish()
+	return rs.GetVaultsByTitleWithContext(context.Background(), title)
+}
 
-	filter := url.QueryEscape(fmt.Sprintf("title eq \"%s\"", title))
-	itemURL := fmt.Sprintf("/v1/vaults?filter=%s", filter)
-	request, err := rs.buildRequest(http.MethodGet, itemURL, http.NoBody, span)
+func (rs *restClient) GetVaultsByTitleWithContext(ctx context.Context, title string) ([]onepassword.Vault, error) {
+	span, ctx := rs.startSpan(ctx, "GetVaultsByTitle")
+	defer span.End()
+
+	return rs.listVaults(ctx, filter.Title().Eq(title), span)
+}
+
+// listVaults gets the vaults that match the filter, or all vaults if the filter is empty.
+func (rs *restClient) listVaults(ctx context.Context, f filter.Filter, span trace.Span) ([]onepassword.Vault, error) {
+	vaultURL := filteredPath("/v1/vaults", f)
+	request, err := rs.buildRequest(ctx, http.MethodGet, vaultURL, http.NoBody, span)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := rs.client.Do(request)
+	response, err := rs.do(request)
 	if err != nil {
 		return nil, err
 	}
@@ -224,14 +333,28 @@ func (rs *restClient) GetVaultsByTitle(title string) ([]onepassword.Vault, error
 	return vaults, nil
 }
 
-func (rs *restClient) getVaultUUID(vaultQuery string) (string, error) {
+// filteredPath adds the filter to the query of the URL, unless it is empty.
+func filteredPath(path string, f filter.Filter) string {
+	if f.IsZero() {
+		return path
+	}
+	return path + "?filter=" + url.QueryEscape(f.String())
+}
+
+func (rs *restClient) getVaultUUID(ctx context.Context, vaultQuery string) (string, error) {
+	return resolveVaultUUID(ctx, rs, vaultQuery)
+}
+
+// resolveVaultUUID returns the UUID of the vault identified by vaultQuery, looking up the vault by its title
+// with the given client if vaultQuery is not a UUID.
+func resolveVaultUUID(ctx context.Context, client Client, vaultQuery string) (string, error) {
 	if vaultQuery == "" {
 		return "", fmt.Errorf("Please provide either the vault name or its ID.")
 	}
 	if isValidUUID(vaultQuery) {
 		return vaultQuery, nil
 	}
-	vault, err := rs.GetVaultByTitle(vaultQuery)
+	vault, err := client.GetVaultByTitleWithContext(ctx, vaultQuery)
 	if err != nil {
 		return "", err
 	}
@@ -240,43 +363,56 @@ func (rs *restClient) getVaultUUID(vaultQuery string) (string, error) {
 
 // GetItem Get a specific Item from the 1Password Connect API by either title or UUID
 func (rs *restClient) GetItem(itemQuery string, vaultQuery string) (*onepassword.Item, error) {
-	span := rs.tracer.StartSpan("GetItem")
-	defer span.Finish()
+	return rs.GetItemWithContext(context.Background(), itemQuery, vaultQuery)
+}
+
+// GetItemWithContext Get a specific Item from the 1Password Connect API by either title or UUID
+func (rs *restClient) GetItemWithContext(ctx context.Context, itemQuery string, vaultQuery string) (*onepassword.Item, error) {
+	span, ctx := rs.startSpan(ctx, "GetItem")
+	defer span.End()
 
 	if itemQuery == "" {
 		return nil, fmt.Errorf("Please provide either the item name or its ID.")
 	}
 
 	if isValidUUID(itemQuery) {
-		item, err := rs.GetItemByUUID(itemQuery, vaultQuery)
+		item, err := rs.GetItemByUUIDWithContext(ctx, itemQuery, vaultQuery)
 		if item != nil {
 			return item, err
 		}
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 	}
-	return rs.GetItemByTitle(itemQuery, vaultQuery)
+	return rs.GetItemByTitleWithContext(ctx, itemQuery, vaultQuery)
 }
 
 // GetItemByUUID Get a specific Item from the 1Password Connect API by its UUID
 func (rs *restClient) GetItemByUUID(uuid string, vaultQuery string) (*onepassword.Item, error) {
+	return rs.GetItemByUUIDWithContext(context.Background(), uuid, vaultQuery)
+}
+
+// GetItemByUUIDWithContext Get a specific Item from the 1Password Connect API by its UUID
+func (rs *restClient) GetItemByUUIDWithContext(ctx context.Context, uuid string, vaultQuery string) (*onepassword.Item, error) {
 	if !isValidUUID(uuid) {
 		return nil, itemUUIDError
 	}
 
-	vaultUUID, err := rs.getVaultUUID(vaultQuery)
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	span := rs.tracer.StartSpan("GetItemByUUID")
-	defer span.Finish()
+	span, ctx := rs.startSpan(ctx, "GetItemByUUID")
+	defer span.End()
 
 	itemURL := fmt.Sprintf("/v1/vaults/%s/items/%s", vaultUUID, uuid)
-	request, err := rs.buildRequest(http.MethodGet, itemURL, http.NoBody, span)
+	request, err := rs.buildRequest(ctx, http.MethodGet, itemURL, http.NoBody, span)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := rs.client.Do(request)
+	response, err := rs.do(request)
 	if err != nil {
 		return nil, err
 	}
@@ -289,99 +425,255 @@ func (rs *restClient) GetItemByUUID(uuid string, vaultQuery string) (*onepasswor
 }
 
 func (rs *restClient) GetItemByTitle(title string, vaultQuery string) (*onepassword.Item, error) {
-	vaultUUID, err := rs.getVaultUUID(vaultQuery)
+	return rs.GetItemByTitleWithContext(context.Background(), title, vaultQuery)
+}
+
+func (rs *restClient) GetItemByTitleWithContext(ctx context.Context, title string, vaultQuery string) (*onepassword.Item, error) {
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	span := rs.tracer.StartSpan("GetItemByTitle")
-	defer span.Finish()
-	items, err := rs.GetItemsByTitle(title, vaultUUID)
+	span, ctx := rs.startSpan(ctx, "GetItemByTitle")
+	defer span.End()
+	items, err := rs.GetItemsByTitleWithContext(ctx, title, vaultUUID)
 	if err != nil {
 		return nil, err
 	}
 
-	if len(items) != 1 {
-		return nil, fmt.Errorf("Found %d item(s) in vault %q with title %q", len(items), vaultUUID, title)
+	switch len(items) {
+	case 0:
+		return nil, &classifiedError{class: ErrItemNotFound, err: fmt.Errorf("Found 0 item(s) in vault %q with title %q", vaultUUID, title)}
+	case 1:
+		return &items[0], nil
 	}
-
-	return &items[0], nil
+	ids := make([]string, len(items))
+	for i, item := range items {
+		ids[i] = item.ID
+	}
+	return nil, &AmbiguousTitleError{Title: title, VaultID: vaultUUID, IDs: ids}
 }
 
 func (rs *restClient) GetItemsByTitle(title string, vaultQuery string) ([]onepassword.Item, error) {
-	vaultUUID, err := rs.getVaultUUID(vaultQuery)
+	return rs.GetItemsByTitleWithContext(context.Background(), title, vaultQuery)
+}
+
+func (rs *restClient) GetItemsByTitleWithContext(ctx context.Context, title string, vaultQuery string) ([]onepassword.Item, error) {
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	span, ctx := rs.startSpan(ctx, "GetItemsByTitle")
+	defer span.End()
+
+	itemSummaries, err := rs.listItemSummaries(ctx, vaultUUID, filter.Title().Eq(title), span)
 	if err != nil {
 		return nil, err
 	}
 
-	span := rs.tracer.StartSpan("GetItemsByTitle")
-	defer span.Finish()
+	return rs.fetchItems(ctx, vaultUUID, summaryIDs(itemSummaries))
+}
+
+// GetItems Get the overviews of all items in a vault. The items have no sections, fields or files, so
+// GetItemSummaries or GetItemsFull are usually a better fit.
+func (rs *restClient) GetItems(vaultQuery string) ([]onepassword.Item, error) {
+	return rs.GetItemsWithContext(context.Background(), vaultQuery)
+}
 
-	filter := url.QueryEscape(fmt.Sprintf("title eq \"%s\"", title))
-	itemURL := fmt.Sprintf("/v1/vaults/%s/items?filter=%s", vaultUUID, filter)
-	request, err := rs.buildRequest(http.MethodGet, itemURL, http.NoBody, span)
+// GetItemsWithContext Get the overviews of all items in a vault, or of those that match the filter set with
+// WithFilter. The items have no sections, fields or files, so GetItemSummariesWithContext or
+// GetItemsFullWithContext are usually a better fit.
+func (rs *restClient) GetItemsWithContext(ctx context.Context, vaultQuery string, opts ...ListOption) ([]onepassword.Item, error) {
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := rs.client.Do(request)
+	span, ctx := rs.startSpan(ctx, "GetItems")
+	defer span.End()
+
+	itemURL := filteredPath(fmt.Sprintf("/v1/vaults/%s/items", vaultUUID), newListConfig(opts).filter)
+	request, err := rs.buildRequest(ctx, http.MethodGet, itemURL, http.NoBody, span)
 	if err != nil {
 		return nil, err
 	}
 
-	var itemSummaries []onepassword.Item
-	if err := parseResponse(response, http.StatusOK, &itemSummaries); err != nil {
+	response, err := rs.do(request)
+	if err != nil {
 		return nil, err
 	}
 
-	items := make([]onepassword.Item, len(itemSummaries))
-	for i, itemSummary := range itemSummaries {
-		tempItem, err := rs.GetItem(itemSummary.ID, itemSummary.Vault.ID)
-		if err != nil {
-			return nil, err
-		}
-		items[i] = *tempItem
+	var items []onepassword.Item
+	if err := parseResponse(response, http.StatusOK, &items); err != nil {
+		return nil, err
 	}
 
 	return items, nil
 }
 
-func (rs *restClient) GetItems(vaultQuery string) ([]onepassword.Item, error) {
-	vaultUUID, err := rs.getVaultUUID(vaultQuery)
+// GetItemSummaries Get the overviews of all items in a vault
+func (rs *restClient) GetItemSummaries(vaultQuery string) ([]onepassword.ItemSummary, error) {
+	return rs.GetItemSummariesWithContext(context.Background(), vaultQuery)
+}
+
+// GetItemSummariesWithContext Get the overviews of all items in a vault, or of those that match the filter set
+// with WithFilter
+func (rs *restClient) GetItemSummariesWithContext(ctx context.Context, vaultQuery string, opts ...ListOption) ([]onepassword.ItemSummary, error) {
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	span := rs.tracer.StartSpan("GetItems")
-	defer span.Finish()
+	span, ctx := rs.startSpan(ctx, "GetItemSummaries")
+	defer span.End()
 
-	itemURL := fmt.Sprintf("/v1/vaults/%s/items", vaultUUID)
-	request, err := rs.buildRequest(http.MethodGet, itemURL, http.NoBody, span)
+	return rs.listItemSummaries(ctx, vaultUUID, newListConfig(opts).filter, span)
+}
+
+// listItemSummaries gets the overviews of the items in the vault that match the filter, or of all items in the
+// vault if the filter is empty.
+func (rs *restClient) listItemSummaries(ctx context.Context, vaultUUID string, f filter.Filter, span trace.Span) ([]onepassword.ItemSummary, error) {
+	itemURL := filteredPath(fmt.Sprintf("/v1/vaults/%s/items", vaultUUID), f)
+	request, err := rs.buildRequest(ctx, http.MethodGet, itemURL, http.NoBody, span)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := rs.client.Do(request)
+	response, err := rs.do(request)
 	if err != nil {
 		return nil, err
 	}
 
-	var items []onepassword.Item
-	if err := parseResponse(response, http.StatusOK, &items); err != nil {
+	var summaries []onepassword.ItemSummary
+	if err := parseResponse(response, http.StatusOK, &summaries); err != nil {
+		return nil, err
+	}
+
+	return summaries, nil
+}
+
+// GetItemsByID Get the items with the given UUIDs from a vault, in the same order
+func (rs *restClient) GetItemsByID(vaultQuery string, ids []string) ([]onepassword.Item, error) {
+	return rs.GetItemsByIDWithContext(context.Background(), vaultQuery, ids)
+}
+
+// GetItemsByIDWithContext Get the items with the given UUIDs from a vault, in the same order
+func (rs *restClient) GetItemsByIDWithContext(ctx context.Context, vaultQuery string, ids []string) ([]onepassword.Item, error) {
+	for _, id := range ids {
+		if !isValidUUID(id) {
+			return nil, itemUUIDError
+		}
+	}
+
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	span, ctx := rs.startSpan(ctx, "GetItemsByID")
+	defer span.End()
+
+	return rs.fetchItems(ctx, vaultUUID, ids)
+}
+
+// GetItemsFull Get all items in a vault including their fields, unlike GetItems which only returns their overviews
+func (rs *restClient) GetItemsFull(vaultQuery string) ([]onepassword.Item, error) {
+	return rs.GetItemsFullWithContext(context.Background(), vaultQuery)
+}
+
+// GetItemsFullWithContext Get all items in a vault including their fields, unlike GetItems which only returns
+// their overviews
+func (rs *restClient) GetItemsFullWithContext(ctx context.Context, vaultQuery string) ([]onepassword.Item, error) {
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	span, ctx := rs.startSpan(ctx, "GetItemsFull")
+	defer span.End()
+
+	itemSummaries, err := rs.listItemSummaries(ctx, vaultUUID, filter.Filter{}, span)
+	if err != nil {
 		return nil, err
 	}
 
+	return rs.fetchItems(ctx, vaultUUID, summaryIDs(itemSummaries))
+}
+
+// fetchItems gets the items with the given UUIDs from the vault, fetching up to rs.concurrency items at the same
+// time. The items are returned in the order of ids. Once fetching an item failed, no further items are fetched
+// and the first error is returned.
+func (rs *restClient) fetchItems(ctx context.Context, vaultUUID string, ids []string) ([]onepassword.Item, error) {
+	concurrency := rs.concurrency
+	if concurrency <= 0 {
+		concurrency = defaultConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	items := make([]onepassword.Item, len(ids))
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	slots := make(chan struct{}, concurrency)
+	for i, id := range ids {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, id string) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			item, err := rs.GetItemByUUIDWithContext(ctx, id, vaultUUID)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			items[i] = *item
+		}(i, id)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return items, nil
 }
 
-func (rs *restClient) getItemUUID(itemQuery, vaultQuery string) (string, error) {
+// summaryIDs returns the IDs of the given item summaries.
+func summaryIDs(summaries []onepassword.ItemSummary) []string {
+	ids := make([]string, len(summaries))
+	for i, summary := range summaries {
+		ids[i] = summary.ID
+	}
+	return ids
+}
+
+func (rs *restClient) getItemUUID(ctx context.Context, itemQuery, vaultQuery string) (string, error) {
 	if itemQuery == "" {
 		return "", fmt.Errorf("Please provide either the item name or its ID.")
 	}
 	if isValidUUID(itemQuery) {
 		return itemQuery, nil
 	}
-	item, err := rs.GetItemByTitle(itemQuery, vaultQuery)
+	item, err := rs.GetItemByTitleWithContext(ctx, itemQuery, vaultQuery)
 	if err != nil {
 		return "", err
 	}
@@ -390,13 +682,18 @@ func (rs *restClient) getItemUUID(itemQuery, vaultQuery string) (string, error)
 
 // CreateItem Create a new item in a specified vault
 func (rs *restClient) CreateItem(item *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
-	vaultUUID, err := rs.getVaultUUID(vaultQuery)
+	return rs.CreateItemWithContext(context.Background(), item, vaultQuery)
+}
+
+// CreateItemWithContext Create a new item in a specified vault
+func (rs *restClient) CreateItemWithContext(ctx context.Context, item *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	span := rs.tracer.StartSpan("CreateItem")
-	defer span.Finish()
+	span, ctx := rs.startSpan(ctx, "CreateItem")
+	defer span.End()
 
 	itemURL := fmt.Sprintf("/v1/vaults/%s/items", vaultUUID)
 	itemBody, err := json.Marshal(item)
@@ -404,12 +701,12 @@ func (rs *restClient) CreateItem(item *onepassword.Item, vaultQuery string) (*on
 		return nil, err
 	}
 
-	request, err := rs.buildRequest(http.MethodPost, itemURL, bytes.NewBuffer(itemBody), span)
+	request, err := rs.buildRequest(ctx, http.MethodPost, itemURL, bytes.NewBuffer(itemBody), span)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := rs.client.Do(request)
+	response, err := rs.do(request)
 	if err != nil {
 		return nil, err
 	}
@@ -424,8 +721,13 @@ func (rs *restClient) CreateItem(item *onepassword.Item, vaultQuery string) (*on
 
 // UpdateItem Update a new item in a specified vault
 func (rs *restClient) UpdateItem(item *onepassword.Item, vaultUUID string) (*onepassword.Item, error) {
-	span := rs.tracer.StartSpan("UpdateItem")
-	defer span.Finish()
+	return rs.UpdateItemWithContext(context.Background(), item, vaultUUID)
+}
+
+// UpdateItemWithContext Update a new item in a specified vault
+func (rs *restClient) UpdateItemWithContext(ctx context.Context, item *onepassword.Item, vaultUUID string) (*onepassword.Item, error) {
+	span, ctx := rs.startSpan(ctx, "UpdateItem")
+	defer span.End()
 
 	itemURL := fmt.Sprintf("/v1/vaults/%s/items/%s", item.Vault.ID, item.ID)
 	itemBody, err := json.Marshal(item)
@@ -433,36 +735,219 @@ func (rs *restClient) UpdateItem(item *onepassword.Item, vaultUUID string) (*one
 		return nil, err
 	}
 
-	request, err := rs.buildRequest(http.MethodPut, itemURL, bytes.NewBuffer(itemBody), span)
+	request, err := rs.buildRequest(ctx, http.MethodPut, itemURL, bytes.NewBuffer(itemBody), span)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := rs.do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var newItem onepassword.Item
+	if err := parseResponse(response, http.StatusOK, &newItem); err != nil {
+		return nil, err
+	}
+
+	return &newItem, nil
+}
+
+// PatchItem Apply a list of RFC 6902 JSON Patch operations to an item in a specified vault
+func (rs *restClient) PatchItem(itemQuery string, vaultQuery string, ops []onepassword.PatchOperation) (*onepassword.Item, error) {
+	return rs.PatchItemWithContext(context.Background(), itemQuery, vaultQuery, ops)
+}
+
+// PatchItemWithContext Apply a list of RFC 6902 JSON Patch operations to an item in a specified vault
+func (rs *restClient) PatchItemWithContext(ctx context.Context, itemQuery string, vaultQuery string, ops []onepassword.PatchOperation) (*onepassword.Item, error) {
+	if err := rs.requireCapability(ctx, CapPatch); err != nil {
+		return nil, err
+	}
+
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+	itemUUID, err := rs.getItemUUID(ctx, itemQuery, vaultUUID)
+	if err != nil {
+		return nil, err
+	}
+
+	span, ctx := rs.startSpan(ctx, "PatchItem")
+	defer span.End()
+
+	itemURL := fmt.Sprintf("/v1/vaults/%s/items/%s", vaultUUID, itemUUID)
+	patchBody, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := rs.buildRequest(ctx, http.MethodPatch, itemURL, bytes.NewBuffer(patchBody), span)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := rs.do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var newItem onepassword.Item
+	if err := parseResponse(response, http.StatusOK, &newItem); err != nil {
+		return nil, err
+	}
+
+	return &newItem, nil
+}
+
+// UpdateItemDiff Update an item by sending only the changes between the original and the modified item,
+// instead of replacing the whole item like UpdateItem does. If the items do not differ, no request is made
+// and the modified item is returned.
+func (rs *restClient) UpdateItemDiff(original *onepassword.Item, modified *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	return rs.UpdateItemDiffWithContext(context.Background(), original, modified, vaultQuery)
+}
+
+// UpdateItemDiffWithContext Update an item by sending only the changes between the original and the modified item,
+// instead of replacing the whole item like UpdateItemWithContext does. If the items do not differ, no request is
+// made and the modified item is returned.
+func (rs *restClient) UpdateItemDiffWithContext(ctx context.Context, original *onepassword.Item, modified *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	ops, err := onepassword.DiffItems(original, modified)
+	if err != nil {
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return modified, nil
+	}
+	if original.Vault.ID != "" {
+		vaultQuery = original.Vault.ID
+	}
+	return rs.PatchItemWithContext(ctx, original.ID, vaultQuery, ops)
+}
+
+// UpdateItemChecked Update an item in a specified vault, if it was not modified since it was read
+func (rs *restClient) UpdateItemChecked(item *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	return rs.UpdateItemCheckedWithContext(context.Background(), item, vaultQuery)
+}
+
+// UpdateItemCheckedWithContext Update an item in a specified vault, if it was not modified since it was read.
+// The current version of the item is read from Connect before the item is written. If it does not match
+// item.Version, or Connect rejects the update with 409 Conflict, a *VersionConflictError is returned.
+// Note that the item could still be modified between the version check and the update.
+func (rs *restClient) UpdateItemCheckedWithContext(ctx context.Context, item *onepassword.Item, vaultQuery string) (*onepassword.Item, error) {
+	span, ctx := rs.startSpan(ctx, "UpdateItemChecked")
+	defer span.End()
+
+	if item.Version == 0 {
+		return nil, fmt.Errorf("item %s has no version to check against", item.ID)
+	}
+	vaultUUID := item.Vault.ID
+	if vaultUUID == "" {
+		var err error
+		if vaultUUID, err = rs.getVaultUUID(ctx, vaultQuery); err != nil {
+			return nil, err
+		}
+	}
+
+	return rs.putItemIfVersion(ctx, item, vaultUUID, item.Version, span)
+}
+
+// putItemIfVersion writes the item, which was read at the given version, if the current version of the item in
+// Connect still matches it. Otherwise, or if Connect rejects the update with 409 Conflict, a *VersionConflictError
+// is returned.
+func (rs *restClient) putItemIfVersion(ctx context.Context, item *onepassword.Item, vaultUUID string, version int, span trace.Span) (*onepassword.Item, error) {
+	current, err := rs.GetItemByUUIDWithContext(ctx, item.ID, vaultUUID)
+	if err != nil {
+		return nil, err
+	}
+	if current.Version != version {
+		return nil, &VersionConflictError{ItemID: item.ID, ExpectedVersion: version, CurrentVersion: current.Version}
+	}
+
+	itemURL := fmt.Sprintf("/v1/vaults/%s/items/%s", vaultUUID, item.ID)
+	itemBody, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := rs.buildRequest(ctx, http.MethodPut, itemURL, bytes.NewBuffer(itemBody), span)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := rs.client.Do(request)
+	response, err := rs.do(request)
 	if err != nil {
 		return nil, err
 	}
 
 	var newItem onepassword.Item
 	if err := parseResponse(response, http.StatusOK, &newItem); err != nil {
+		var apiErr *onepassword.Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusConflict {
+			conflict := &VersionConflictError{ItemID: item.ID, ExpectedVersion: version}
+			if current, err := rs.GetItemByUUIDWithContext(ctx, item.ID, vaultUUID); err == nil {
+				conflict.CurrentVersion = current.Version
+			}
+			return nil, conflict
+		}
 		return nil, err
 	}
 
 	return &newItem, nil
 }
 
+// ModifyItem Read an item, change it with modify and write it back, retrying if it was modified in the meantime
+func (rs *restClient) ModifyItem(itemQuery string, vaultQuery string, modify func(*onepassword.Item) error) (*onepassword.Item, error) {
+	return rs.ModifyItemWithContext(context.Background(), itemQuery, vaultQuery, modify)
+}
+
+// ModifyItemWithContext Read an item, change it with modify and write it back, unless the version of the item in
+// Connect changed since it was read, which is checked right before the item is written. In that case, it is read again and modify is called with the new version, up to
+// maxModifyAttempts times. An error returned by modify aborts the update and is returned as is.
+func (rs *restClient) ModifyItemWithContext(ctx context.Context, itemQuery string, vaultQuery string, modify func(*onepassword.Item) error) (*onepassword.Item, error) {
+	span, ctx := rs.startSpan(ctx, "ModifyItem")
+	defer span.End()
+
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		item, err := rs.GetItemWithContext(ctx, itemQuery, vaultUUID)
+		if err != nil {
+			return nil, err
+		}
+		// Read the same item again on a conflict, even if modify changed its title
+		itemQuery = item.ID
+		version := item.Version
+
+		if err := modify(item); err != nil {
+			return nil, err
+		}
+		updated, err := rs.putItemIfVersion(ctx, item, vaultUUID, version, span)
+		if err == nil || !errors.Is(err, ErrVersionConflict) || attempt >= maxModifyAttempts {
+			return updated, err
+		}
+	}
+}
+
 // DeleteItem Delete a new item in a specified vault
 func (rs *restClient) DeleteItem(item *onepassword.Item, vaultUUID string) error {
-	span := rs.tracer.StartSpan("DeleteItem")
-	defer span.Finish()
+	return rs.DeleteItemWithContext(context.Background(), item, vaultUUID)
+}
+
+// DeleteItemWithContext Delete a new item in a specified vault
+func (rs *restClient) DeleteItemWithContext(ctx context.Context, item *onepassword.Item, vaultUUID string) error {
+	span, ctx := rs.startSpan(ctx, "DeleteItem")
+	defer span.End()
 
 	itemURL := fmt.Sprintf("/v1/vaults/%s/items/%s", item.Vault.ID, item.ID)
-	request, err := rs.buildRequest(http.MethodDelete, itemURL, http.NoBody, span)
+	request, err := rs.buildRequest(ctx, http.MethodDelete, itemURL, http.NoBody, span)
 	if err != nil {
 		return err
 	}
 
-	response, err := rs.client.Do(request)
+	response, err := rs.do(request)
 	if err != nil {
 		return err
 	}
@@ -476,24 +961,29 @@ func (rs *restClient) DeleteItem(item *onepassword.Item, vaultUUID string) error
 
 // DeleteItemByID Delete a new item in a specified vault, specifying the item's uuid
 func (rs *restClient) DeleteItemByID(itemUUID string, vaultQuery string) error {
+	return rs.DeleteItemByIDWithContext(context.Background(), itemUUID, vaultQuery)
+}
+
+// DeleteItemByIDWithContext Delete a new item in a specified vault, specifying the item's uuid
+func (rs *restClient) DeleteItemByIDWithContext(ctx context.Context, itemUUID string, vaultQuery string) error {
 	if !isValidUUID(itemUUID) {
 		return itemUUIDError
 	}
-	vaultUUID, err := rs.getVaultUUID(vaultQuery)
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
 	if err != nil {
 		return err
 	}
 
-	span := rs.tracer.StartSpan("DeleteItemByID")
-	defer span.Finish()
+	span, ctx := rs.startSpan(ctx, "DeleteItemByID")
+	defer span.End()
 
 	itemURL := fmt.Sprintf("/v1/vaults/%s/items/%s", vaultUUID, itemUUID)
-	request, err := rs.buildRequest(http.MethodDelete, itemURL, http.NoBody, span)
+	request, err := rs.buildRequest(ctx, http.MethodDelete, itemURL, http.NoBody, span)
 	if err != nil {
 		return err
 	}
 
-	response, err := rs.client.Do(request)
+	response, err := rs.do(request)
 	if err != nil {
 		return err
 	}
@@ -507,40 +997,53 @@ func (rs *restClient) DeleteItemByID(itemUUID string, vaultQuery string) error {
 
 // DeleteItemByTitle Delete a new item in a specified vault, specifying the item's title
 func (rs *restClient) DeleteItemByTitle(title string, vaultQuery string) error {
-	span := rs.tracer.StartSpan("DeleteItemByTitle")
-	defer span.Finish()
+	return rs.DeleteItemByTitleWithContext(context.Background(), title, vaultQuery)
+}
 
-	item, err := rs.GetItemByTitle(title, vaultQuery)
+// DeleteItemByTitleWithContext Delete a new item in a specified vault, specifying the item's title
+func (rs *restClient) DeleteItemByTitleWithContext(ctx context.Context, title string, vaultQuery string) error {
+	span, ctx := rs.startSpan(ctx, "DeleteItemByTitle")
+	defer span.End()
+
+	item, err := rs.GetItemByTitleWithContext(ctx, title, vaultQuery)
 	if err != nil {
 		return err
 	}
 
-	return rs.DeleteItem(item, item.Vault.ID)
+	return rs.DeleteItemWithContext(ctx, item, item.Vault.ID)
 }
 
 func (rs *restClient) GetFiles(itemQuery string, vaultQuery string) ([]onepassword.File, error) {
-	vaultUUID, err := rs.getVaultUUID(vaultQuery)
+	return rs.GetFilesWithContext(context.Background(), itemQuery, vaultQuery)
+}
+
+func (rs *restClient) GetFilesWithContext(ctx context.Context, itemQuery string, vaultQuery string) ([]onepassword.File, error) {
+	if err := rs.requireCapability(ctx, CapFiles); err != nil {
+		return nil, err
+	}
+
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
 	if err != nil {
 		return nil, err
 	}
-	itemUUID, err := rs.getItemUUID(itemQuery, vaultQuery)
+	itemUUID, err := rs.getItemUUID(ctx, itemQuery, vaultQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	span := rs.tracer.StartSpan("GetFiles")
-	defer span.Finish()
+	span, ctx := rs.startSpan(ctx, "GetFiles")
+	defer span.End()
 
 	jsonURL := fmt.Sprintf("/v1/vaults/%s/items/%s/files", vaultUUID, itemUUID)
-	request, err := rs.buildRequest(http.MethodGet, jsonURL, http.NoBody, span)
+	request, err := rs.buildRequest(ctx, http.MethodGet, jsonURL, http.NoBody, span)
 	if err != nil {
 		return nil, err
 	}
-	response, err := rs.client.Do(request)
+	response, err := rs.do(request)
 	if err != nil {
 		return nil, err
 	}
-	if err := expectMinimumConnectVersion(response, version{1, 3, 0}); err != nil {
+	if err := expectMinimumConnectVersion(response, CapFiles); err != nil {
 		return nil, err
 	}
 	var files []onepassword.File
@@ -554,32 +1057,42 @@ func (rs *restClient) GetFiles(itemQuery string, vaultQuery string) ([]onepasswo
 // GetFile Get a specific File in a specified item.
 // This does not include the file contents. Call GetFileContent() to load the file's content.
 func (rs *restClient) GetFile(uuid string, itemQuery string, vaultQuery string) (*onepassword.File, error) {
+	return rs.GetFileWithContext(context.Background(), uuid, itemQuery, vaultQuery)
+}
+
+// GetFileWithContext Get a specific File in a specified item.
+// This does not include the file contents. Call GetFileContentWithContext() to load the file's content.
+func (rs *restClient) GetFileWithContext(ctx context.Context, uuid string, itemQuery string, vaultQuery string) (*onepassword.File, error) {
+	if err := rs.requireCapability(ctx, CapFiles); err != nil {
+		return nil, err
+	}
+
 	if !isValidUUID(uuid) {
 		return nil, fileUUIDError
 	}
-	vaultUUID, err := rs.getVaultUUID(vaultQuery)
+	vaultUUID, err := rs.getVaultUUID(ctx, vaultQuery)
 	if err != nil {
 		return nil, err
 	}
-	itemUUID, err := rs.getItemUUID(itemQuery, vaultQuery)
+	itemUUID, err := rs.getItemUUID(ctx, itemQuery, vaultQuery)
 	if err != nil {
 		return nil, err
 	}
 
-	span := rs.tracer.StartSpan("GetFile")
-	defer span.Finish()
+	span, ctx := rs.startSpan(ctx, "GetFile")
+	defer span.End()
 
 	itemURL := fmt.Sprintf("/v1/vaults/%s/items/%s/files/%s", vaultUUID, itemUUID, uuid)
-	request, err := rs.buildRequest(http.MethodGet, itemURL, http.NoBody, span)
+	request, err := rs.buildRequest(ctx, http.MethodGet, itemURL, http.NoBody, span)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := rs.client.Do(request)
+	response, err := rs.do(request)
 	if err != nil {
 		return nil, err
 	}
-	if err := expectMinimumConnectVersion(response, version{1, 3, 0}); err != nil {
+	if err := expectMinimumConnectVersion(response, CapFiles); err != nil {
 		return nil, err
 	}
 
@@ -594,10 +1107,16 @@ func (rs *restClient) GetFile(uuid string, itemQuery string, vaultQuery string)
 // GetFileContent retrieves the file's content.
 // If the file's content have previously been fetched, those contents are returned without making another request.
 func (rs *restClient) GetFileContent(file *onepassword.File) ([]byte, error) {
+	return rs.GetFileContentWithContext(context.Background(), file)
+}
+
+// GetFileContentWithContext retrieves the file's content.
+// If the file's content have previously been fetched, those contents are returned without making another request.
+func (rs *restClient) GetFileContentWithContext(ctx context.Context, file *onepassword.File) ([]byte, error) {
 	if content, err := file.Content(); err == nil {
 		return content, nil
 	}
-	response, err := rs.retrieveDocumentContent(file)
+	response, err := rs.retrieveDocumentContent(ctx, file)
 	if err != nil {
 		return nil, err
 	}
@@ -610,10 +1129,15 @@ func (rs *restClient) GetFileContent(file *onepassword.File) ([]byte, error) {
 }
 
 func (rs *restClient) DownloadFile(file *onepassword.File, targetDirectory string, overwriteIfExists bool) (string, error) {
-	response, err := rs.retrieveDocumentContent(file)
+	return rs.DownloadFileWithContext(context.Background(), file, targetDirectory, overwriteIfExists)
+}
+
+func (rs *restClient) DownloadFileWithContext(ctx context.Context, file *onepassword.File, targetDirectory string, overwriteIfExists bool) (string, error) {
+	response, err := rs.retrieveDocumentContent(ctx, file)
 	if err != nil {
 		return "", err
 	}
+	defer response.Body.Close()
 
 	path := filepath.Join(targetDirectory, filepath.Base(file.Name))
 
@@ -637,26 +1161,34 @@ func (rs *restClient) DownloadFile(file *onepassword.File, targetDirectory strin
 	}
 	defer osFile.Close()
 	if _, err = io.Copy(osFile, response.Body); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
 		return "", err
 	}
 
 	return path, nil
 }
 
-func (rs *restClient) retrieveDocumentContent(file *onepassword.File) (*http.Response, error) {
-	span := rs.tracer.StartSpan("GetFileContent")
-	defer span.Finish()
+func (rs *restClient) retrieveDocumentContent(ctx context.Context, file *onepassword.File) (*http.Response, error) {
+	if err := rs.requireCapability(ctx, CapFiles); err != nil {
+		return nil, err
+	}
+
+	span, ctx := rs.startSpan(ctx, "GetFileContent")
+	defer span.End()
 
-	request, err := rs.buildRequest(http.MethodGet, file.ContentPath, http.NoBody, span)
+	request, err := rs.buildRequest(ctx, http.MethodGet, file.ContentPath, http.NoBody, span)
 	if err != nil {
 		return nil, err
 	}
 
-	response, err := rs.client.Do(request)
+	response, err := rs.do(request)
 	if err != nil {
 		return nil, err
 	}
-	if err := expectMinimumConnectVersion(response, version{1, 3, 0}); err != nil {
+	if err := expectMinimumConnectVersion(response, CapFiles); err != nil {
+		response.Body.Close()
 		return nil, err
 	}
 	return response, nil
@@ -674,159 +1206,310 @@ func createFile(path string) (*os.File, error) {
 	return osFile, nil
 }
 
-func (rs *restClient) buildRequest(method string, path string, body io.Reader, span opentracing.Span) (*http.Request, error) {
-	url := fmt.Sprintf("%s%s", rs.URL, path)
+// ServerInfo describes the Connect server the client is connected to
+type ServerInfo struct {
+	Version ServerVersion
+}
+
+// Heartbeat Check that the Connect server is running and reachable
+func (rs *restClient) Heartbeat() error {
+	return rs.HeartbeatWithContext(context.Background())
+}
 
-	request, err := http.NewRequest(method, url, body)
+// HeartbeatWithContext Check that the Connect server is running and reachable. It does not check whether the
+// server can serve vaults and items, use HealthWithContext for that.
+func (rs *restClient) HeartbeatWithContext(ctx context.Context) error {
+	span, ctx := rs.startSpan(ctx, "Heartbeat")
+	defer span.End()
+
+	request, err := rs.buildRequest(ctx, http.MethodGet, "/heartbeat", http.NoBody, span)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	request.Header.Set("Content-Type", "application/json")
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", rs.Token))
-	request.Header.Set("User-Agent", rs.userAgent)
+	response, err := rs.do(request)
+	if err != nil {
+		return err
+	}
+	_, err = readResponseBody(response, http.StatusOK)
+	return err
+}
 
-	ext.SpanKindRPCClient.Set(span)
-	ext.HTTPUrl.Set(span, path)
-	ext.HTTPMethod.Set(span, method)
+// Health Get the state of the Connect server and the services it depends on
+func (rs *restClient) Health() (*onepassword.ServerHealth, error) {
+	return rs.HealthWithContext(context.Background())
+}
 
-	rs.tracer.Inject(span.Context(), opentracing.HTTPHeaders, opentracing.HTTPHeadersCarrier(request.Header))
+// HealthWithContext Get the state of the Connect server and the services it depends on
+func (rs *restClient) HealthWithContext(ctx context.Context) (*onepassword.ServerHealth, error) {
+	span, ctx := rs.startSpan(ctx, "Health")
+	defer span.End()
 
-	return request, nil
+	request, err := rs.buildRequest(ctx, http.MethodGet, "/health", http.NoBody, span)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := rs.do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	var health onepassword.ServerHealth
+	if err := parseResponse(response, http.StatusOK, &health); err != nil {
+		return nil, err
+	}
+
+	return &health, nil
 }
 
-func loadToStruct(item *parsedItem, config reflect.Value) error {
-	t := config.Type()
-	for i := 0; i < t.NumField(); i++ {
-		value := config.Field(i)
-		field := t.Field(i)
+// ServerInfo Get information about the Connect server, such as its version
+func (rs *restClient) ServerInfo() (*ServerInfo, error) {
+	return rs.ServerInfoWithContext(context.Background())
+}
 
-		if !value.CanSet() {
-			return fmt.Errorf("cannot load config into private fields")
-		}
+// ServerInfoWithContext Get information about the Connect server, such as its version
+func (rs *restClient) ServerInfoWithContext(ctx context.Context) (*ServerInfo, error) {
+	span, ctx := rs.startSpan(ctx, "ServerInfo")
+	defer span.End()
 
-		item.fields = append(item.fields, &field)
-		item.values = append(item.values, &value)
+	detected, err := rs.requestServerVersion(ctx)
+	if err != nil {
+		return nil, err
 	}
-	return nil
+
+	rs.detectedVersionMu.Lock()
+	rs.detectedVersion = &detected
+	rs.versionProbeErr = nil
+	rs.detectedVersionMu.Unlock()
+
+	return &ServerInfo{Version: detected.exported()}, nil
 }
 
-// LoadStructFromItem Load configuration values based on struct tag from one 1P item.
-// It accepts as parameters item title/UUID and vault title/UUID.
-func (rs *restClient) LoadStructFromItem(i interface{}, itemQuery string, vaultQuery string) error {
-	if itemQuery == "" {
-		return fmt.Errorf("Please provide either the item name or its ID.")
-	}
-	if isValidUUID(itemQuery) {
-		return rs.LoadStructFromItemByUUID(i, itemQuery, vaultQuery)
-	}
-	return rs.LoadStructFromItemByTitle(i, itemQuery, vaultQuery)
+// GetAPIActivity Get a page of the API requests made to Connect, skipping the first offset requests
+func (rs *restClient) GetAPIActivity(limit int, offset int) ([]onepassword.APIRequest, error) {
+	return rs.GetAPIActivityWithContext(context.Background(), limit, offset)
 }
 
-// LoadStructFromItemByUUID Load configuration values based on struct tag from one 1P item.
-func (rs *restClient) LoadStructFromItemByUUID(i interface{}, itemUUID string, vaultQuery string) error {
-	vaultUUID, err := rs.getVaultUUID(vaultQuery)
-	if err != nil {
-		return err
+// GetAPIActivityWithContext Get a page of the API requests made to Connect, skipping the first offset requests.
+// At most limit requests are returned. A limit of 0 or lower uses the default limit of the Connect API.
+// Use NewAPIActivityIterator to page through all requests.
+func (rs *restClient) GetAPIActivityWithContext(ctx context.Context, limit int, offset int) ([]onepassword.APIRequest, error) {
+	span, ctx := rs.startSpan(ctx, "GetAPIActivity")
+	defer span.End()
+
+	query := url.Values{}
+	if limit > 0 {
+		query.Set("limit", strconv.Itoa(limit))
 	}
-	if !isValidUUID(itemUUID) {
-		return itemUUIDError
+	if offset > 0 {
+		query.Set("offset", strconv.Itoa(offset))
+	}
+	activityURL := "/v1/activity"
+	if len(query) > 0 {
+		activityURL += "?" + query.Encode()
 	}
-	config, err := checkStruct(i)
+
+	request, err := rs.buildRequest(ctx, http.MethodGet, activityURL, http.NoBody, span)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	item := parsedItem{}
-	item.itemUUID = itemUUID
-	item.vaultUUID = vaultUUID
 
-	if err := loadToStruct(&item, config); err != nil {
-		return err
+	response, err := rs.do(request)
+	if err != nil {
+		return nil, err
 	}
-	if err := setValuesForTag(rs, &item, false); err != nil {
-		return err
+
+	var requests []onepassword.APIRequest
+	if err := parseResponse(response, http.StatusOK, &requests); err != nil {
+		return nil, err
 	}
 
-	return nil
+	return requests, nil
 }
 
-// LoadStructFromItemByTitle Load configuration values based on struct tag from one 1P item
-func (rs *restClient) LoadStructFromItemByTitle(i interface{}, itemTitle string, vaultQuery string) error {
-	vaultUUID, err := rs.getVaultUUID(vaultQuery)
-	if err != nil {
-		return err
-	}
+func (rs *restClient) buildRequest(ctx context.Context, method string, path string, body io.Reader, span trace.Span) (*http.Request, error) {
+	url := fmt.Sprintf("%s%s", rs.URL, path)
 
-	config, err := checkStruct(i)
+	request, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	item := parsedItem{}
-	item.itemTitle = itemTitle
-	item.vaultUUID = vaultUUID
 
-	if err := loadToStruct(&item, config); err != nil {
-		return err
-	}
-	if err := setValuesForTag(rs, &item, true); err != nil {
-		return err
+	for key, values := range rs.headers {
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
 	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", rs.Token))
+	request.Header.Set("User-Agent", rs.userAgent)
 
-	return nil
+	rs.traceRequest(span, request, path)
+
+	return request, nil
 }
 
-// LoadStruct Load configuration values based on struct tag
-func (rs *restClient) LoadStruct(i interface{}) error {
-	config, err := checkStruct(i)
-	if err != nil {
-		return err
+// do sends the request to Connect, retrying it according to the client's retry policy. If the request failed
+// because its context was cancelled or its deadline exceeded, the context's error is returned as is so callers
+// can compare it against context.Canceled and context.DeadlineExceeded. GET requests are shared with identical
+// requests in flight if request coalescing is enabled.
+func (rs *restClient) do(request *http.Request) (*http.Response, error) {
+	if rs.requests != nil && request.Method == http.MethodGet {
+		start := time.Now()
+		response, shared, err := rs.requests.do(request, rs.send)
+		if shared {
+			// The request was sent for another caller, but this caller's operation is observed as well
+			rs.observe(request, response, 0, time.Since(start), err)
+		}
+		return response, err
 	}
+	return rs.send(request)
+}
 
-	t := config.Type()
+// send sends the request like do, without deduplicating it with identical requests in flight.
+func (rs *restClient) send(request *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, retries, err := rs.doWithRetries(request)
+	rs.observe(request, response, retries, time.Since(start), err)
+	return response, err
+}
 
-	// Multiple fields may be from a single item so we will collect them
-	items := map[string]parsedItem{}
+// observe reports the outcome of a request to the span of its operation, the logger and the metrics recorder.
+func (rs *restClient) observe(request *http.Request, response *http.Response, retries int, duration time.Duration, err error) {
+	statusCode := responseStatus(response, err)
+	traceResponse(request, statusCode, err)
+	rs.logRequest(request, statusCode, retries, duration, err)
+	rs.recordRequest(request, statusCode, duration, err)
+}
 
-	// Fetch the Vault from the environment
-	vaultUUID, envVarFound := os.LookupEnv(envVaultVar)
+// responseStatus returns the status code of the response, or of the last response received before retries ran
+// out, or 0 if no response was received.
+func responseStatus(response *http.Response, err error) int {
+	if response != nil {
+		return response.StatusCode
+	}
+	var retryErr *RetryError
+	if errors.As(err, &retryErr) {
+		return retryErr.StatusCode
+	}
+	return 0
+}
 
-	for i := 0; i < t.NumField(); i++ {
-		value := config.Field(i)
-		field := t.Field(i)
-		tag := field.Tag.Get(itemTag)
+// doWithRetries sends the request like do, and also returns the number of times the request was retried.
+func (rs *restClient) doWithRetries(request *http.Request) (*http.Response, int, error) {
+	ctx := request.Context()
+	for attempt := 1; ; attempt++ {
+		response, err := rs.client.Do(request)
+		if err != nil && ctx.Err() != nil {
+			return nil, attempt - 1, ctx.Err()
+		}
+		if response != nil && response.Request == nil {
+			// The request is needed to classify errors returned in the response
+			response.Request = request
+		}
 
-		if tag == "" {
-			continue
+		retry := attempt < rs.retryPolicy.maxAttempts() && rs.retryPolicy.shouldRetry(request, response, err)
+		var wait time.Duration
+		if retry {
+			wait, retry = rs.retryPolicy.backoff(attempt, response)
 		}
 
-		if !value.CanSet() {
-			return fmt.Errorf("Cannot load config into private fields")
+		if !retry {
+			if attempt == 1 {
+				return response, 0, err
+			}
+			// Unsuccessful responses are returned as errors too, so that callers learn how often the request was
+			// retried, even if the last status is not one that is retried
+			if err == nil && response.StatusCode >= http.StatusBadRequest {
+				body, readErr := io.ReadAll(response.Body)
+				response.Body.Close()
+				if readErr != nil {
+					return nil, attempt - 1, &RetryError{Retries: attempt - 1, StatusCode: response.StatusCode, Err: readErr}
+				}
+				err = errorFromResponseBody(request, response.StatusCode, body)
+			}
+			if err != nil {
+				return nil, attempt - 1, &RetryError{Retries: attempt - 1, StatusCode: responseStatus(response, nil), Err: err}
+			}
+			return response, attempt - 1, nil
 		}
 
-		itemVault, err := vaultUUIDForField(&field, vaultUUID, envVarFound)
-		if err != nil {
-			return err
+		if response != nil {
+			io.Copy(io.Discard, response.Body)
+			response.Body.Close()
 		}
-		if !isValidUUID(itemVault) {
-			return vaultUUIDError
+		if err := sleep(ctx, wait); err != nil {
+			return nil, attempt, err
 		}
 
-		key := fmt.Sprintf("%s/%s", itemVault, tag)
-		parsed := items[key]
-		parsed.vaultUUID = itemVault
-		parsed.itemTitle = tag
-		parsed.fields = append(parsed.fields, &field)
-		parsed.values = append(parsed.values, &value)
-		items[key] = parsed
-	}
-
-	for _, item := range items {
-		if err := setValuesForTag(rs, &item, true); err != nil {
-			return err
+		request, err = rewindRequest(request)
+		if err != nil {
+			return nil, attempt, err
 		}
 	}
+}
 
-	return nil
+// LoadStructFromItem Load configuration values based on struct tag from one 1P item.
+// It accepts as parameters item title/UUID and vault title/UUID.
+func (rs *restClient) LoadStructFromItem(i interface{}, itemQuery string, vaultQuery string) error {
+	return rs.LoadStructFromItemWithContext(context.Background(), i, itemQuery, vaultQuery)
+}
+
+// LoadStructFromItemWithContext Load configuration values based on struct tag from one 1P item.
+// It accepts as parameters item title/UUID and vault title/UUID.
+func (rs *restClient) LoadStructFromItemWithContext(ctx context.Context, i interface{}, itemQuery string, vaultQuery string) error {
+	return loadStructFromItem(ctx, rs, i, itemQuery, vaultQuery)
+}
+
+// LoadStructFromItemByUUID Load configuration values based on struct tag from one 1P item.
+func (rs *restClient) LoadStructFromItemByUUID(i interface{}, itemUUID string, vaultQuery string) error {
+	return rs.LoadStructFromItemByUUIDWithContext(context.Background(), i, itemUUID, vaultQuery)
+}
+
+// LoadStructFromItemByUUIDWithContext Load configuration values based on struct tag from one 1P item.
+func (rs *restClient) LoadStructFromItemByUUIDWithContext(ctx context.Context, i interface{}, itemUUID string, vaultQuery string) error {
+	return loadStructFromItemByUUID(ctx, rs, i, itemUUID, vaultQuery)
+}
+
+// LoadStructFromItemByTitle Load configuration values based on struct tag from one 1P item
+func (rs *restClient) LoadStructFromItemByTitle(i interface{}, itemTitle string, vaultQuery string) error {
+	return rs.LoadStructFromItemByTitleWithContext(context.Background(), i, itemTitle, vaultQuery)
+}
+
+// LoadStructFromItemByTitleWithContext Load configuration values based on struct tag from one 1P item
+func (rs *restClient) LoadStructFromItemByTitleWithContext(ctx context.Context, i interface{}, itemTitle string, vaultQuery string) error {
+	return loadStructFromItemByTitle(ctx, rs, i, itemTitle, vaultQuery)
+}
+
+// LoadStruct Load configuration values based on struct tag
+func (rs *restClient) LoadStruct(i interface{}) error {
+	return rs.LoadStructWithContext(context.Background(), i)
+}
+
+// LoadStructWithContext Load configuration values based on struct tag
+func (rs *restClient) LoadStructWithContext(ctx context.Context, i interface{}) error {
+	return loadStruct(ctx, rs, i)
+}
+
+// StrictLoadStruct Check whether the LoadStruct methods load in strict mode, as set with WithStrictLoadStruct
+func (rs *restClient) StrictLoadStruct() bool {
+	return rs.strictLoadStruct
+}
+
+// ResolveReference Get the value of the field or file a secret reference such as op://vault/item/section/field
+// points to
+func (rs *restClient) ResolveReference(ref string) (string, error) {
+	return rs.ResolveReferenceWithContext(context.Background(), ref)
+}
+
+// ResolveReferenceWithContext Get the value of the field or file a secret reference such as
+// op://vault/item/section/field points to
+func (rs *restClient) ResolveReferenceWithContext(ctx context.Context, ref string) (string, error) {
+	span, ctx := rs.startSpan(ctx, "ResolveReference")
+	defer span.End()
+
+	return resolveReference(ctx, rs, ref)
 }
 
 func parseResponse(resp *http.Response, expectedStatusCode int, result interface{}) error {
@@ -849,20 +1532,26 @@ func readResponseBody(resp *http.Response, expectedStatusCode int) ([]byte, erro
 		return nil, err
 	}
 	if resp.StatusCode != expectedStatusCode {
-		var errResp onepassword.Error
-		if json.Valid(body) {
-			if err := json.Unmarshal(body, &errResp); err != nil {
-				return nil, fmt.Errorf("decoding error response: %s", err)
-			}
-		} else {
-			errResp.StatusCode = resp.StatusCode
-			errResp.Message = http.StatusText(resp.StatusCode)
-		}
-		return nil, &errResp
+		return nil, errorFromResponseBody(resp.Request, resp.StatusCode, body)
 	}
 	return body, nil
 }
 
+// errorFromResponseBody returns the error described by the body of an unsuccessful response from Connect to the
+// given request.
+func errorFromResponseBody(request *http.Request, statusCode int, body []byte) error {
+	var errResp onepassword.Error
+	if json.Valid(body) {
+		if err := json.Unmarshal(body, &errResp); err != nil {
+			return fmt.Errorf("decoding error response: %s", err)
+		}
+	} else {
+		errResp.StatusCode = statusCode
+		errResp.Message = http.StatusText(statusCode)
+	}
+	return classifyAPIError(request, statusCode, &errResp)
+}
+
 func isValidUUID(u string) bool {
 	r := regexp.MustCompile("^[a-z0-9]{26}$")
 	return r.MatchString(u)
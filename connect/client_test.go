@@ -2,19 +2,25 @@ package connect
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"reflect"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
-	"github.com/opentracing/opentracing-go"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace/noop"
 
+	"github.com/1Password/connect-sdk-go/filter"
 	"github.com/1Password/connect-sdk-go/onepassword"
 )
 
@@ -64,11 +70,14 @@ func TestMain(m *testing.M) {
 	mockHTTPClient = &mockClient{}
 
 	testClient = &restClient{
-		URL:       validHost,
-		Token:     validToken,
-		userAgent: testUserAgent,
-		tracer:    opentracing.GlobalTracer(),
-		client:    mockHTTPClient,
+		URL:        validHost,
+		Token:      validToken,
+		userAgent:  testUserAgent,
+		tracer:     noop.NewTracerProvider().Tracer(tracerName),
+		propagator: propagation.TraceContext{},
+		client:     mockHTTPClient,
+		// Avoid requesting the server version in tests that do not expect it
+		detectedVersion: &serverVersion{version: testServerDefaultVersion},
 	}
 
 	requestCount = 0
@@ -393,6 +402,195 @@ func Test_restClient_GetItemByNonUniqueTitle(t *testing.T) {
 	}
 }
 
+func Test_restClient_GetItemWithContextCanceled(t *testing.T) {
+	mockHTTPClient.Dofunc = respectContext(getItem)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	item, err := testClient.GetItemWithContext(ctx, testID, testID)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, item)
+}
+
+func Test_restClient_GetItemsByTitleWithContextCanceledBetweenRequests(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	// Let the list request succeed and cancel the context before the items are fetched
+	mockHTTPClient.Dofunc = respectContext(func(req *http.Request) (*http.Response, error) {
+		if strings.Contains(req.URL.RequestURI(), "test-item") {
+			defer cancel()
+		}
+		return listItemsOrGetItem(req)
+	})
+
+	items, err := testClient.GetItemsByTitleWithContext(ctx, "test-item", testVaultUUID)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, items)
+}
+
+func Test_restClient_GetItemSummaries(t *testing.T) {
+	ids := []string{testItemUUID, testID}
+	mockHTTPClient.Dofunc = serveItemsWithIDs(ids)
+
+	summaries, err := testClient.GetItemSummaries(testVaultUUID)
+
+	assert.Nil(t, err)
+	if assert.Len(t, summaries, len(ids)) {
+		assert.Equal(t, testItemUUID, summaries[0].ID)
+
+		item, err := summaries[0].Load(testClient)
+		assert.Nil(t, err)
+		assert.Equal(t, testItemUUID, item.ID)
+		assert.Equal(t, "wendy", item.Fields[0].Value)
+	}
+}
+
+func Test_restClient_GetItemSummariesWithFilter(t *testing.T) {
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/v1/vaults/"+testVaultUUID+"/items", req.URL.Path)
+		assert.Equal(t, `tag eq "prod" and title sw "db-"`, req.URL.Query().Get("filter"))
+		return serveItemsWithIDs([]string{testItemUUID})(req)
+	}
+
+	summaries, err := testClient.GetItemSummariesWithContext(context.Background(), testVaultUUID,
+		WithFilter(filter.And(filter.Tag().Eq("prod"), filter.Title().Sw("db-"))))
+
+	assert.Nil(t, err)
+	assert.Len(t, summaries, 1)
+
+	items, err := testClient.GetItemsWithContext(context.Background(), testVaultUUID,
+		WithFilter(filter.And(filter.Tag().Eq("prod"), filter.Title().Sw("db-"))))
+
+	assert.Nil(t, err)
+	assert.Len(t, items, 1)
+}
+
+func Test_restClient_GetItemsByTitleEscapesQuotes(t *testing.T) {
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, `title eq "say \"hi\""`, req.URL.Query().Get("filter"))
+		return respondJSON([]onepassword.Item{})(req)
+	}
+
+	items, err := testClient.GetItemsByTitle(`say "hi"`, testVaultUUID)
+
+	assert.Nil(t, err)
+	assert.Empty(t, items)
+}
+
+func Test_restClient_GetVaultsWithFilter(t *testing.T) {
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, `title co "prod"`, req.URL.Query().Get("filter"))
+		return listVaults(req)
+	}
+
+	vaults, err := testClient.GetVaultsWithContext(context.Background(), WithFilter(filter.Title().Co("prod")))
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, vaults)
+}
+
+func Test_restClient_GetItemsByID(t *testing.T) {
+	ids := []string{testItemUUID, testID, "3c47aa139ef74d7ca17918035e"}
+	mockHTTPClient.Dofunc = serveItemsWithIDs(ids)
+
+	items, err := testClient.GetItemsByID(testVaultUUID, ids)
+
+	assert.Nil(t, err)
+	if assert.Len(t, items, len(ids)) {
+		for i, item := range items {
+			assert.Equal(t, ids[i], item.ID)
+			assert.Equal(t, "wendy", item.Fields[0].Value)
+		}
+	}
+}
+
+func Test_restClient_GetItemsByIDInvalidID(t *testing.T) {
+	mockHTTPClient.Dofunc = serveItemsWithIDs(nil)
+
+	items, err := testClient.GetItemsByID(testVaultUUID, []string{testItemUUID, "not-a-uuid"})
+
+	assert.ErrorIs(t, err, ErrInvalidID)
+	assert.Nil(t, items)
+}
+
+func Test_restClient_GetItemsByIDError(t *testing.T) {
+	missingID := "3c47aa139ef74d7ca17918035e"
+	serve := serveItemsWithIDs([]string{testItemUUID})
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, missingID) {
+			return respondError(apiError(http.StatusNotFound, "Item not found"))(req)
+		}
+		return serve(req)
+	}
+
+	items, err := testClient.GetItemsByID(testVaultUUID, []string{testItemUUID, missingID})
+
+	assert.ErrorIs(t, err, ErrItemNotFound)
+	assert.Nil(t, items)
+}
+
+func Test_restClient_GetItemsFull(t *testing.T) {
+	ids := []string{testItemUUID, testID}
+	mockHTTPClient.Dofunc = serveItemsWithIDs(ids)
+
+	items, err := testClient.GetItemsFull(testVaultUUID)
+
+	assert.Nil(t, err)
+	if assert.Len(t, items, len(ids)) {
+		for i, item := range items {
+			assert.Equal(t, ids[i], item.ID)
+			assert.NotEmpty(t, item.Fields)
+		}
+	}
+}
+
+func Test_restClient_GetItemsFullConcurrency(t *testing.T) {
+	ids := make([]string, 10)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%026d", i)
+	}
+	serve := serveItemsWithIDs(ids)
+
+	var mu sync.Mutex
+	inFlight, maxInFlight := 0, 0
+	mock := &mockClient{Dofunc: func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		inFlight++
+		if inFlight > maxInFlight {
+			maxInFlight = inFlight
+		}
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		return serve(req)
+	}}
+	client := NewClient(validHost, validToken, WithHTTPClient(mock), WithConcurrency(3))
+
+	items, err := client.GetItemsFull(testVaultUUID)
+
+	assert.Nil(t, err)
+	assert.Len(t, items, len(ids))
+	assert.LessOrEqual(t, maxInFlight, 3)
+}
+
+func Test_restClient_GetVaultsWithContextPropagatesContext(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "value", req.Context().Value(ctxKey{}))
+		return listVaults(req)
+	}
+
+	_, err := testClient.GetVaultsWithContext(ctx)
+	assert.Nil(t, err)
+}
+
 func Test_restClient_CreateItem(t *testing.T) {
 	mockHTTPClient.Dofunc = createItem
 	item, err := testClient.CreateItem(generateItem(defaultVault), defaultVault)
@@ -448,6 +646,305 @@ func Test_restClient_UpdateItemError(t *testing.T) {
 	}
 }
 
+func Test_restClient_PatchItem(t *testing.T) {
+	ops := []onepassword.PatchOperation{
+		onepassword.ReplaceOperation(onepassword.FieldPath("password", "value"), "new-password"),
+		onepassword.RemoveOperation(onepassword.SectionPath("section")),
+	}
+
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, http.MethodPatch, req.Method)
+		assert.Equal(t, fmt.Sprintf("/v1/vaults/%s/items/%s", testVaultUUID, testItemUUID), req.URL.Path)
+
+		var received []onepassword.PatchOperation
+		if err := json.NewDecoder(req.Body).Decode(&received); err != nil {
+			return nil, err
+		}
+		assert.Equal(t, []onepassword.PatchOperation{
+			{Op: onepassword.PatchOperationReplace, Path: "/fields/password/value", Value: "new-password"},
+			{Op: onepassword.PatchOperationRemove, Path: "/sections/section"},
+		}, received)
+
+		return getComplexItem(req)
+	}
+	item, err := testClient.PatchItem(testItemUUID, testVaultUUID, ops)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, item)
+}
+
+func Test_restClient_PatchItemError(t *testing.T) {
+	errResult := apiError(http.StatusBadRequest, "Invalid patch")
+	mockHTTPClient.Dofunc = respondError(errResult)
+
+	item, err := testClient.PatchItem(testItemUUID, testVaultUUID, []onepassword.PatchOperation{
+		onepassword.ReplaceOperation(onepassword.PatchPathTitle, "New title"),
+	})
+
+	assert.ErrorIs(t, err, errResult)
+	assert.Nil(t, item)
+}
+
+func Test_restClient_UpdateItemDiff(t *testing.T) {
+	original := generateItem(testVaultUUID)
+	modified := generateItem(testVaultUUID)
+	modified.Fields[0].Value = "john"
+
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, http.MethodPatch, req.Method)
+		assert.Equal(t, fmt.Sprintf("/v1/vaults/%s/items/%s", testVaultUUID, testItemUUID), req.URL.Path)
+
+		var received []onepassword.PatchOperation
+		if err := json.NewDecoder(req.Body).Decode(&received); err != nil {
+			return nil, err
+		}
+		assert.Equal(t, []onepassword.PatchOperation{
+			{Op: onepassword.PatchOperationReplace, Path: "/fields/" + testID + "/value", Value: "john"},
+		}, received)
+
+		return getComplexItem(req)
+	}
+	item, err := testClient.UpdateItemDiff(original, modified, "")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, item)
+}
+
+func Test_restClient_UpdateItemDiffWithoutChanges(t *testing.T) {
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		t.Error("Expected no request to be made")
+		return nil, fmt.Errorf("unexpected request")
+	}
+	modified := generateItem(testVaultUUID)
+	item, err := testClient.UpdateItemDiff(generateItem(testVaultUUID), modified, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, modified, item)
+}
+
+func Test_restClient_UpdateItemDiffNilItem(t *testing.T) {
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		t.Error("Expected no request to be made")
+		return nil, fmt.Errorf("unexpected request")
+	}
+
+	_, err := testClient.UpdateItemDiff(nil, generateItem(testVaultUUID), "")
+	assert.NotNil(t, err)
+
+	_, err = testClient.UpdateItemDiff(generateItem(testVaultUUID), nil, "")
+	assert.NotNil(t, err)
+}
+
+func Test_restClient_UpdateItemChecked(t *testing.T) {
+	server := &versionedItemServer{versions: []int{3}}
+	mockHTTPClient.Dofunc = server.Do
+
+	item := generateItem(testVaultUUID)
+	item.Version = 3
+	updated, err := testClient.UpdateItemChecked(item, "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 4, updated.Version)
+	assert.Equal(t, 1, server.puts)
+}
+
+func Test_restClient_UpdateItemCheckedConflict(t *testing.T) {
+	server := &versionedItemServer{versions: []int{4}}
+	mockHTTPClient.Dofunc = server.Do
+
+	item := generateItem(testVaultUUID)
+	item.Version = 3
+	updated, err := testClient.UpdateItemChecked(item, "")
+
+	assert.Nil(t, updated)
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	var conflict *VersionConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, &VersionConflictError{ItemID: testItemUUID, ExpectedVersion: 3, CurrentVersion: 4}, conflict)
+	}
+	assert.Equal(t, 0, server.puts)
+}
+
+func Test_restClient_UpdateItemCheckedConflictResponse(t *testing.T) {
+	server := &versionedItemServer{versions: []int{3, 5}, conflictOnPut: true}
+	mockHTTPClient.Dofunc = server.Do
+
+	item := generateItem(testVaultUUID)
+	item.Version = 3
+	_, err := testClient.UpdateItemChecked(item, "")
+
+	var conflict *VersionConflictError
+	if assert.ErrorAs(t, err, &conflict) {
+		assert.Equal(t, 5, conflict.CurrentVersion)
+	}
+}
+
+func Test_restClient_UpdateItemCheckedWithoutVersion(t *testing.T) {
+	server := &versionedItemServer{versions: []int{3}}
+	mockHTTPClient.Dofunc = server.Do
+
+	_, err := testClient.UpdateItemChecked(generateItem(testVaultUUID), "")
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 0, server.gets+server.puts)
+}
+
+func Test_restClient_ModifyItem(t *testing.T) {
+	// The item is modified by someone else between the first read and the version check
+	server := &versionedItemServer{versions: []int{1, 2}}
+	mockHTTPClient.Dofunc = server.Do
+
+	calls := 0
+	updated, err := testClient.ModifyItem(testItemUUID, testVaultUUID, func(item *onepassword.Item) error {
+		calls++
+		item.Title = "rotated"
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 4, server.gets, "the version should be checked right before every update")
+	assert.Equal(t, 1, server.puts, "an update that would overwrite a newer version should not be sent")
+	assert.Equal(t, "rotated", updated.Title)
+	assert.Equal(t, 3, updated.Version)
+}
+
+func Test_restClient_ModifyItemAborted(t *testing.T) {
+	server := &versionedItemServer{versions: []int{1}}
+	mockHTTPClient.Dofunc = server.Do
+
+	abort := fmt.Errorf("nothing to rotate")
+	_, err := testClient.ModifyItem(testItemUUID, testVaultUUID, func(item *onepassword.Item) error {
+		return abort
+	})
+
+	assert.Equal(t, abort, err)
+	assert.Equal(t, 0, server.puts)
+}
+
+func Test_restClient_ModifyItemGivesUp(t *testing.T) {
+	server := &versionedItemServer{conflictOnPut: true, versions: []int{1}}
+	mockHTTPClient.Dofunc = server.Do
+
+	calls := 0
+	_, err := testClient.ModifyItem(testItemUUID, testVaultUUID, func(item *onepassword.Item) error {
+		calls++
+		return nil
+	})
+
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.Equal(t, maxModifyAttempts, calls)
+	assert.Equal(t, maxModifyAttempts, server.puts)
+}
+
+func Test_restClient_GetAPIActivity(t *testing.T) {
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/v1/activity", req.URL.Path)
+		assert.Equal(t, "limit=10&offset=20", req.URL.RawQuery)
+
+		body := `[{
+			"requestId": "3e4a7c1b-0b8c-4d6a-9c4b-2a6d1f5e8b9a",
+			"timestamp": "2021-05-18T14:37:07.843Z",
+			"action": "UPDATE",
+			"result": "SUCCESS",
+			"actor": {"id": "actor", "account": "account", "jti": "jti", "userAgent": "agent", "requestIp": "10.0.0.1"},
+			"resource": {"type": "ITEM", "vault": {"id": "` + testVaultUUID + `"}, "item": {"id": "` + testItemUUID + `"}, "itemVersion": 4}
+		}]`
+		return &http.Response{
+			Status:     http.StatusText(http.StatusOK),
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Header:     req.Header,
+		}, nil
+	}
+	requests, err := testClient.GetAPIActivity(10, 20)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []onepassword.APIRequest{{
+		RequestID: "3e4a7c1b-0b8c-4d6a-9c4b-2a6d1f5e8b9a",
+		Timestamp: time.Date(2021, 5, 18, 14, 37, 7, 843000000, time.UTC),
+		Action:    onepassword.APIRequestActionUpdate,
+		Result:    onepassword.APIRequestResultSuccess,
+		Actor:     onepassword.APIRequestActor{ID: "actor", Account: "account", JTI: "jti", UserAgent: "agent", RequestIP: "10.0.0.1"},
+		Resource: onepassword.APIRequestResource{
+			Type:        onepassword.APIRequestResourceTypeItem,
+			Vault:       onepassword.APIRequestVault{ID: testVaultUUID},
+			Item:        onepassword.APIRequestItem{ID: testItemUUID},
+			ItemVersion: 4,
+		},
+	}}, requests)
+}
+
+func Test_restClient_Heartbeat(t *testing.T) {
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/heartbeat", req.URL.Path)
+		return &http.Response{
+			Status:     http.StatusText(http.StatusOK),
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(".")),
+			Header:     http.Header{},
+		}, nil
+	}
+
+	assert.Nil(t, testClient.Heartbeat())
+}
+
+func Test_restClient_HeartbeatError(t *testing.T) {
+	errResult := apiError(http.StatusServiceUnavailable, "Service Unavailable")
+	mockHTTPClient.Dofunc = respondError(errResult)
+
+	assert.ErrorIs(t, testClient.Heartbeat(), errResult)
+}
+
+func Test_restClient_Health(t *testing.T) {
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "/health", req.URL.Path)
+		body := `{
+			"name": "1Password Connect API",
+			"version": "1.5.0",
+			"dependencies": [
+				{"service": "sqlite", "status": "ACTIVE", "message": "Connected to ~/1password.sqlite"},
+				{"service": "sync", "status": "ACTIVE"}
+			]
+		}`
+		return &http.Response{
+			Status:     http.StatusText(http.StatusOK),
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(body)),
+			Header:     http.Header{},
+		}, nil
+	}
+	health, err := testClient.Health()
+
+	assert.Nil(t, err)
+	assert.Equal(t, &onepassword.ServerHealth{
+		Name:    "1Password Connect API",
+		Version: "1.5.0",
+		Dependencies: []onepassword.ServiceDependency{
+			{Service: "sqlite", Status: "ACTIVE", Message: "Connected to ~/1password.sqlite"},
+			{Service: "sync", Status: "ACTIVE"},
+		},
+	}, health)
+}
+
+func Test_restClient_ServerInfo(t *testing.T) {
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		header := http.Header{}
+		header.Set(VersionHeaderKey, "1.5.7")
+		return &http.Response{
+			Status:     http.StatusText(http.StatusOK),
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(strings.NewReader(".")),
+			Header:     header,
+		}, nil
+	}
+	info, err := testClient.ServerInfo()
+
+	assert.Nil(t, err)
+	assert.Equal(t, &ServerInfo{Version: ServerVersion{Major: 1, Minor: 5, Patch: 7}}, info)
+	assert.Equal(t, "1.5.7", info.Version.String())
+}
+
 func Test_restClient_DeleteItem(t *testing.T) {
 	mockHTTPClient.Dofunc = deleteItem
 	err := testClient.DeleteItem(generateItem(defaultVault), "")
@@ -549,7 +1046,7 @@ func Test_restClient_loadStructFromItem(t *testing.T) {
 
 	err := loadToStruct(&item, reflect.ValueOf(&c).Elem())
 	assert.Nil(t, err)
-	err = setValuesForTag(testClient, &item, false)
+	err = setValuesForTag(context.Background(), testClient, &item, false)
 	assert.Nil(t, err)
 
 	assert.Equal(t, "wendy", c.Username)
@@ -582,7 +1079,7 @@ func Test_restClient_loadStructFromItemAndOneItem(t *testing.T) {
 
 	err := loadToStruct(&item, reflect.ValueOf(&c).Elem())
 	assert.Nil(t, err)
-	err = setValuesForTag(testClient, &item, false)
+	err = setValuesForTag(context.Background(), testClient, &item, false)
 	assert.Nil(t, err)
 
 	assert.Equal(t, "wendy", c.Username)
@@ -625,6 +1122,16 @@ func respondErrorWithHtmlBody(apiErr *onepassword.Error) func(req *http.Request)
 	}
 }
 
+// respectContext fails the request the same way net/http does when the request's context is done.
+func respectContext(next func(req *http.Request) (*http.Response, error)) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		if err := req.Context().Err(); err != nil {
+			return nil, &url.Error{Op: req.Method, URL: req.URL.String(), Err: err}
+		}
+		return next(req)
+	}
+}
+
 func listVaults(req *http.Request) (*http.Response, error) {
 	vaults := []onepassword.Vault{
 		{
@@ -912,6 +1419,52 @@ func getFileContent(req *http.Request) (*http.Response, error) {
 	}, nil
 }
 
+// versionedItemServer serves the complex item, returning the given versions for consecutive reads. The last version
+// is returned for all following reads. Updates increment the version sent by the client, unless conflictOnPut is set.
+type versionedItemServer struct {
+	versions      []int
+	conflictOnPut bool
+	gets          int
+	puts          int
+}
+
+func (s *versionedItemServer) Do(req *http.Request) (*http.Response, error) {
+	switch req.Method {
+	case http.MethodGet:
+		item := generateComplexItem(testVaultUUID)
+		item.Version = s.versions[len(s.versions)-1]
+		if s.gets < len(s.versions) {
+			item.Version = s.versions[s.gets]
+		}
+		s.gets++
+		body, _ := json.Marshal(item)
+		return &http.Response{
+			Status:     http.StatusText(http.StatusOK),
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+			Header:     req.Header,
+		}, nil
+	case http.MethodPut:
+		s.puts++
+		if s.conflictOnPut {
+			return respondError(apiError(http.StatusConflict, "item was modified"))(req)
+		}
+		var item onepassword.Item
+		if err := json.NewDecoder(req.Body).Decode(&item); err != nil {
+			return nil, err
+		}
+		item.Version++
+		body, _ := json.Marshal(item)
+		return &http.Response{
+			Status:     http.StatusText(http.StatusOK),
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+			Header:     req.Header,
+		}, nil
+	}
+	return nil, fmt.Errorf("unexpected %s request", req.Method)
+}
+
 func apiError(statusCode int, message string) *onepassword.Error {
 	return &onepassword.Error{
 		StatusCode: statusCode,
@@ -919,6 +1472,22 @@ func apiError(statusCode int, message string) *onepassword.Error {
 	}
 }
 
+// serveItemsWithIDs lists the items with the given IDs and serves each of them in full.
+func serveItemsWithIDs(ids []string) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/items") {
+			summaries := make([]onepassword.Item, len(ids))
+			for i, id := range ids {
+				summaries[i] = onepassword.Item{ID: id, Vault: onepassword.ItemVault{ID: testVaultUUID}}
+			}
+			return respondJSON(summaries)(req)
+		}
+		item := generateItem(testVaultUUID)
+		item.ID = path.Base(req.URL.Path)
+		return respondJSON(item)(req)
+	}
+}
+
 func reset() {
 	requestCount = 0
 	requestFail = false
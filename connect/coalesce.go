@@ -0,0 +1,113 @@
+package connect
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// requestGroup deduplicates identical GET requests that are in flight at the same time, so that concurrent callers
+// share a single request to Connect.
+type requestGroup struct {
+	mu    sync.Mutex
+	calls map[string]*sharedCall
+	// joined is called, if set, when a caller starts waiting for a request in flight
+	joined func(request *http.Request)
+}
+
+// sharedCall is a GET request in flight, and its outcome once done is closed.
+type sharedCall struct {
+	done chan struct{}
+
+	statusCode int
+	status     string
+	header     http.Header
+	body       []byte
+	err        error
+}
+
+func newRequestGroup() *requestGroup {
+	return &requestGroup{calls: map[string]*sharedCall{}}
+}
+
+// do sends the request with send, unless an identical request is already in flight, in which case it waits for
+// the response of that request instead. Every caller receives its own copy of the response, with a fully
+// buffered body, so nothing read from it is shared between callers. shared is true if the response is that of a
+// request sent by another caller, so that send did not observe it for this caller.
+func (g *requestGroup) do(request *http.Request, send func(*http.Request) (*http.Response, error)) (response *http.Response, shared bool, err error) {
+	key := request.Method + " " + request.URL.String()
+
+	g.mu.Lock()
+	call, inFlight := g.calls[key]
+	if !inFlight {
+		call = &sharedCall{done: make(chan struct{})}
+		g.calls[key] = call
+	}
+	g.mu.Unlock()
+
+	if !inFlight {
+		call.complete(send(request))
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		close(call.done)
+		response, err = call.response(request)
+		return response, false, err
+	}
+
+	if g.joined != nil {
+		g.joined(request)
+	}
+	ctx := request.Context()
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, true, ctx.Err()
+	}
+
+	// The shared request was canceled by the caller that sent it, which says nothing about this caller's request.
+	if ctx.Err() == nil && (errors.Is(call.err, context.Canceled) || errors.Is(call.err, context.DeadlineExceeded)) {
+		response, err = send(request)
+		return response, false, err
+	}
+	response, err = call.response(request)
+	return response, true, err
+}
+
+// complete stores the outcome of the shared request, reading the full response body.
+func (c *sharedCall) complete(response *http.Response, err error) {
+	if err != nil {
+		c.err = err
+		return
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		c.err = err
+		return
+	}
+	c.statusCode = response.StatusCode
+	c.status = response.Status
+	c.header = response.Header
+	c.body = body
+}
+
+// response returns a copy of the response of the shared request for the given request.
+func (c *sharedCall) response(request *http.Request) (*http.Response, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return &http.Response{
+		Status:        c.status,
+		StatusCode:    c.statusCode,
+		Header:        c.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(c.body)),
+		ContentLength: int64(len(c.body)),
+		Request:       request,
+	}, nil
+}
@@ -0,0 +1,176 @@
+package connect
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+// blockRequests returns a function that serves requests with dofunc once release is closed.
+func blockRequests(dofunc func(req *http.Request) (*http.Response, error), release <-chan struct{}) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		<-release
+		return dofunc(req)
+	}
+}
+
+// watchSharedRequest counts the callers of client that wait for a request in flight instead of sending their own.
+// The returned function blocks until the GET request to path reaches the mock transport, which holds it until it is
+// released, and the given number of callers wait for it.
+func watchSharedRequest(t *testing.T, client *restClient, mock *countingMock, path string) func(waiting int) {
+	var joined atomic.Int32
+	client.requests.joined = func(*http.Request) { joined.Add(1) }
+	return func(waiting int) {
+		t.Helper()
+		assert.Eventually(t, func() bool {
+			return mock.count(http.MethodGet, path) == 1 && int(joined.Load()) == waiting
+		}, time.Second, time.Millisecond)
+	}
+}
+
+func TestRequestCoalescing(t *testing.T) {
+	release := make(chan struct{})
+	mock := newCountingMock(blockRequests(getItem, release))
+	client := NewClient(validHost, validToken, WithHTTPClient(mock), WithRequestCoalescing()).(*restClient)
+	client.detectedVersion = &serverVersion{version: testServerDefaultVersion}
+
+	path := "/v1/vaults/" + testVaultUUID + "/items/" + testItemUUID
+	waitForSharedRequest := watchSharedRequest(t, client, mock, path)
+
+	const callers = 10
+	items := make([]*onepassword.Item, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			items[i], errs[i] = client.GetItem(testItemUUID, testVaultUUID)
+		}(i)
+	}
+
+	waitForSharedRequest(callers - 1)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, 1, mock.count(http.MethodGet, path))
+	for i := 0; i < callers; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, items[0], items[i])
+	}
+
+	items[0].Fields[0].Value = "changed"
+	assert.NotEqual(t, items[0].Fields[0].Value, items[1].Fields[0].Value, "callers should not share items")
+
+	_, err := client.GetItem(testItemUUID, testVaultUUID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, mock.count(http.MethodGet, path), "requests that are done should not be shared")
+}
+
+func TestRequestCoalescingSharesErrors(t *testing.T) {
+	release := make(chan struct{})
+	mock := newCountingMock(blockRequests(respondError(apiError(http.StatusNotFound, "Item not found")), release))
+	client := NewClient(validHost, validToken, WithHTTPClient(mock), WithRequestCoalescing()).(*restClient)
+	client.detectedVersion = &serverVersion{version: testServerDefaultVersion}
+
+	waitForSharedRequest := watchSharedRequest(t, client, mock, "/v1/vaults/"+testVaultUUID+"/items/"+testItemUUID)
+
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := client.GetItemByUUID(testItemUUID, testVaultUUID)
+			errs <- err
+		}()
+	}
+
+	waitForSharedRequest(1)
+	close(release)
+
+	for i := 0; i < 2; i++ {
+		assert.ErrorIs(t, <-errs, ErrItemNotFound)
+	}
+	assert.Equal(t, 1, mock.total())
+}
+
+func TestRequestCoalescingCanceledSender(t *testing.T) {
+	release := make(chan struct{})
+	mock := newCountingMock(getItem)
+	mock.setDofunc(func(req *http.Request) (*http.Response, error) {
+		if mock.count(req.Method, req.URL.Path) == 1 {
+			<-req.Context().Done()
+			return nil, req.Context().Err()
+		}
+		<-release
+		return getItem(req)
+	})
+	client := NewClient(validHost, validToken, WithHTTPClient(mock), WithRequestCoalescing()).(*restClient)
+	client.detectedVersion = &serverVersion{version: testServerDefaultVersion}
+
+	path := "/v1/vaults/" + testVaultUUID + "/items/" + testItemUUID
+	waitForSharedRequest := watchSharedRequest(t, client, mock, path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	senderErr := make(chan error)
+	go func() {
+		_, err := client.GetItemWithContext(ctx, testItemUUID, testVaultUUID)
+		senderErr <- err
+	}()
+
+	assert.Eventually(t, func() bool { return mock.count(http.MethodGet, path) == 1 }, time.Second, time.Millisecond)
+
+	waiterErr := make(chan error)
+	go func() {
+		_, err := client.GetItem(testItemUUID, testVaultUUID)
+		waiterErr <- err
+	}()
+	waitForSharedRequest(1)
+
+	cancel()
+	assert.ErrorIs(t, <-senderErr, context.Canceled)
+	close(release)
+	assert.NoError(t, <-waiterErr, "a waiting caller should send its own request if the shared one was canceled")
+	assert.Equal(t, 2, mock.count(http.MethodGet, path))
+}
+
+func TestRequestCoalescingObservesEveryCaller(t *testing.T) {
+	release := make(chan struct{})
+	mock := newCountingMock(blockRequests(getItem, release))
+	metrics := &recordingMetrics{}
+	client := NewClient(validHost, validToken, WithHTTPClient(mock), WithRequestCoalescing(), WithMetrics(metrics)).(*restClient)
+	client.detectedVersion = &serverVersion{version: testServerDefaultVersion}
+	waitForSharedRequest := watchSharedRequest(t, client, mock, "/v1/vaults/"+testVaultUUID+"/items/"+testItemUUID)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client.GetItemByUUID(testItemUUID, testVaultUUID)
+		}()
+	}
+	waitForSharedRequest(1)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, []observedRequest{
+		{operation: "GetItemByUUID", statusCode: http.StatusOK},
+		{operation: "GetItemByUUID", statusCode: http.StatusOK},
+	}, metrics.requests)
+	assert.Equal(t, 1, mock.total())
+}
+
+func TestWithRequestCoalescing(t *testing.T) {
+	client := NewClient(validHost, validToken, WithRequestCoalescing()).(*restClient)
+	assert.NotNil(t, client.requests)
+
+	client = NewClient(validHost, validToken).(*restClient)
+	assert.Nil(t, client.requests)
+}
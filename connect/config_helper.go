@@ -1,10 +1,17 @@
 package connect
 
 import (
+	"context"
+	"encoding"
+	"errors"
 	"fmt"
+	"net/url"
+	"os"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/1Password/connect-sdk-go/onepassword"
 )
@@ -15,6 +22,8 @@ const (
 	sectionTag = "opsection"
 	fieldTag   = "opfield"
 	urlTag     = "opurl"
+	// referenceTag holds a secret reference such as op://prod/postgres/password, instead of the other tags
+	referenceTag = "op"
 
 	envVaultVar = "OP_VAULT"
 )
@@ -25,6 +34,88 @@ type parsedItem struct {
 	itemTitle string
 	fields    []*reflect.StructField
 	values    []*reflect.Value
+	// names are the names of the fields, prefixed with the names of the structs they are nested in
+	names []string
+	// strict makes loading fail for fields that have no value in the item and no default
+	strict bool
+}
+
+// add adds a struct field to load from the item.
+func (p *parsedItem) add(field *reflect.StructField, value *reflect.Value, name string) {
+	p.fields = append(p.fields, field)
+	p.values = append(p.values, value)
+	p.names = append(p.names, name)
+}
+
+// query returns the UUID or title the item is fetched by.
+func (p *parsedItem) query() string {
+	if p.itemUUID != "" {
+		return p.itemUUID
+	}
+	return p.itemTitle
+}
+
+// referencedFields are the struct fields tagged with secret references to the same item.
+type referencedFields struct {
+	vault  string
+	item   string
+	refs   []*SecretReference
+	values []*reflect.Value
+	// names are the names of the fields, prefixed with the names of the structs they are nested in
+	names []string
+}
+
+// add adds a struct field to load from the item.
+func (r *referencedFields) add(ref *SecretReference, value *reflect.Value, name string) {
+	r.refs = append(r.refs, ref)
+	r.values = append(r.values, value)
+	r.names = append(r.names, name)
+}
+
+// FieldError is returned by the LoadStruct methods if the value of an item field cannot be loaded into a field of
+// the struct, for example because it cannot be converted to the type of the struct field.
+type FieldError struct {
+	// Field is the name of the struct field, prefixed with the names of the structs it is nested in.
+	Field string
+	// Item is the title or UUID of the item.
+	Item string
+	// Label is the label of the item field, prefixed with the label of its section if it is in one.
+	Label string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("cannot load %q of item %q into field %s: %v", e.Label, e.Item, e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// ErrFieldNotFound is matched by errors.Is if an item has no field with the requested label. It is returned by
+// ResolveReference, and by the LoadStruct methods for struct fields that are required or loaded in strict mode.
+var ErrFieldNotFound = errors.New("field not found")
+
+// LoadStructError is returned by the LoadStruct methods if one or more struct fields could not be loaded. It lists
+// the problems with all fields and items, instead of only the first one. Errors about a single field are
+// *FieldError values.
+type LoadStructError struct {
+	Errors []error
+}
+
+func (e *LoadStructError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	messages := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d fields could not be loaded: %s", len(e.Errors), strings.Join(messages, "; "))
+}
+
+func (e *LoadStructError) Unwrap() []error {
+	return e.Errors
 }
 
 func checkStruct(i interface{}) (reflect.Value, error) {
@@ -40,13 +131,358 @@ func checkStruct(i interface{}) (reflect.Value, error) {
 	return config, nil
 
 }
-func vaultUUIDForField(field *reflect.StructField, vaultUUID string, envVaultFound bool) (string, error) {
+
+func loadToStruct(item *parsedItem, config reflect.Value) error {
+	return loadNestedToStruct(item, config, "")
+}
+
+// loadNestedToStruct adds the fields of config to item like loadToStruct, prefixing their names with prefix.
+func loadNestedToStruct(item *parsedItem, config reflect.Value, prefix string) error {
+	t := config.Type()
+	for i := 0; i < t.NumField(); i++ {
+		value := config.Field(i)
+		field := t.Field(i)
+
+		if isNestedStruct(field) {
+			if !value.CanSet() {
+				continue
+			}
+			if err := loadNestedToStruct(item, nestedStructValue(value), prefix+field.Name+"."); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !value.CanSet() {
+			// Private members of nested structs are left alone, as they cannot be configuration values
+			if prefix != "" {
+				continue
+			}
+			return fmt.Errorf("cannot load config into private fields")
+		}
+
+		item.add(&field, &value, prefix+field.Name)
+	}
+	return nil
+}
+
+// isNestedStruct reports whether the field holds a struct, or a pointer to one, whose own fields are loaded
+// rather than a value of the item. Structs that are not configuration, such as an *http.Client, are left alone, so
+// a struct is only nested if the field has an opitem or opvault tag or the struct has fields with tags.
+func isNestedStruct(field reflect.StructField) bool {
+	t := indirectType(field.Type)
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	switch t {
+	case reflect.TypeOf(onepassword.Item{}), reflect.TypeOf(onepassword.ItemURL{}), reflect.TypeOf(onepassword.ItemSection{}),
+		timeType, urlType:
+		return false
+	}
+	if isTextUnmarshaler(t) {
+		return false
+	}
+	for _, tag := range []string{sectionTag, fieldTag, urlTag, referenceTag} {
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return false
+		}
+	}
+	for _, tag := range []string{itemTag, vaultTag} {
+		if _, ok := field.Tag.Lookup(tag); ok {
+			return true
+		}
+	}
+	return hasTaggedFields(t, map[reflect.Type]bool{})
+}
+
+// hasTaggedFields reports whether the struct type t, or a struct nested in it, has an exported field with one of
+// the tags LoadStruct reads. seen guards against types that refer to themselves.
+func hasTaggedFields(t reflect.Type, seen map[reflect.Type]bool) bool {
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		for _, tag := range []string{vaultTag, itemTag, sectionTag, fieldTag, urlTag, referenceTag} {
+			if _, ok := field.Tag.Lookup(tag); ok {
+				return true
+			}
+		}
+		nested := indirectType(field.Type)
+		if nested.Kind() == reflect.Struct && !seen[nested] && hasTaggedFields(nested, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// nestedStructValue returns the struct held by value, allocating it if value is a nil pointer. value must be
+// settable.
+func nestedStructValue(value reflect.Value) reflect.Value {
+	if value.Kind() != reflect.Ptr {
+		return value
+	}
+	if value.IsNil() {
+		value.Set(reflect.New(value.Type().Elem()))
+	}
+	return value.Elem()
+}
+
+func indirectType(t reflect.Type) reflect.Type {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem()
+	}
+	return t
+}
+
+// loadStructFromItem loads configuration values based on struct tag from one 1P item, using client to fetch it.
+func loadStructFromItem(ctx context.Context, client Client, i interface{}, itemQuery string, vaultQuery string) error {
+	if itemQuery == "" {
+		return fmt.Errorf("Please provide either the item name or its ID.")
+	}
+	if isValidUUID(itemQuery) {
+		return loadStructFromItemByUUID(ctx, client, i, itemQuery, vaultQuery)
+	}
+	return loadStructFromItemByTitle(ctx, client, i, itemQuery, vaultQuery)
+}
+
+func loadStructFromItemByUUID(ctx context.Context, client Client, i interface{}, itemUUID string, vaultQuery string) error {
+	vaultUUID, err := resolveVaultUUID(ctx, client, vaultQuery)
+	if err != nil {
+		return err
+	}
+	if !isValidUUID(itemUUID) {
+		return itemUUIDError
+	}
+	config, err := checkStruct(i)
+	if err != nil {
+		return err
+	}
+	item := parsedItem{}
+	item.itemUUID = itemUUID
+	item.vaultUUID = vaultUUID
+	item.strict = strictLoading(client)
+
+	if err := loadToStruct(&item, config); err != nil {
+		return err
+	}
+	if err := setValuesForTag(ctx, client, &item, false); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func loadStructFromItemByTitle(ctx context.Context, client Client, i interface{}, itemTitle string, vaultQuery string) error {
+	vaultUUID, err := resolveVaultUUID(ctx, client, vaultQuery)
+	if err != nil {
+		return err
+	}
+
+	config, err := checkStruct(i)
+	if err != nil {
+		return err
+	}
+	item := parsedItem{}
+	item.itemTitle = itemTitle
+	item.vaultUUID = vaultUUID
+	item.strict = strictLoading(client)
+
+	if err := loadToStruct(&item, config); err != nil {
+		return err
+	}
+	if err := setValuesForTag(ctx, client, &item, true); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// loadStruct loads configuration values based on struct tag, using client to fetch the items.
+func loadStruct(ctx context.Context, client Client, i interface{}) error {
+	config, err := checkStruct(i)
+	if err != nil {
+		return err
+	}
+
+	// Fetch the Vault from the environment
+	vaultUUID, envVarFound := os.LookupEnv(envVaultVar)
+
+	// Multiple fields may be from a single item so we will collect them
+	fields := structFields{
+		items:         map[string]parsedItem{},
+		references:    map[string]*referencedFields{},
+		envVaultUUID:  vaultUUID,
+		envVaultFound: envVarFound,
+	}
+	if err := fields.collect(config, fieldScope{}); err != nil {
+		return err
+	}
+
+	// Load the items in a fixed order, so that errors are reported in the same order every time
+	keys := make([]string, 0, len(fields.items))
+	for key := range fields.items {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	strict := strictLoading(client)
+	var errs []error
+	for _, key := range keys {
+		item := fields.items[key]
+		item.strict = strict
+		err := setValuesForTag(ctx, client, &item, true)
+		var loadErr *LoadStructError
+		if errors.As(err, &loadErr) {
+			errs = append(errs, loadErr.Errors...)
+		} else if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	keys = keys[:0]
+	for key := range fields.references {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		errs = append(errs, setReferencedValues(ctx, client, fields.references[key])...)
+	}
+
+	if len(errs) > 0 {
+		return &LoadStructError{Errors: errs}
+	}
+	return nil
+}
+
+// StrictLoader is implemented by a Client whose LoadStruct methods load in strict mode, as set with
+// WithStrictLoadStruct. A Client that wraps another one should implement it to pass on the mode of the wrapped
+// Client, like CachingClient does.
+type StrictLoader interface {
+	StrictLoadStruct() bool
+}
+
+// strictLoading reports whether the LoadStruct methods of client load in strict mode.
+func strictLoading(client Client) bool {
+	if loader, ok := client.(StrictLoader); ok {
+		return loader.StrictLoadStruct()
+	}
+	return false
+}
+
+// fieldScope is what the fields of a nested struct inherit from the struct.
+type fieldScope struct {
+	prefix    string
+	itemTitle string
+	vaultUUID string
+}
+
+// structFields collects the fields of a struct by the item they are loaded from.
+type structFields struct {
+	// items holds the fields tagged with opitem, by vault UUID and item title
+	items map[string]parsedItem
+	// references holds the fields tagged with a secret reference, by the vault and item of the reference
+	references    map[string]*referencedFields
+	envVaultUUID  string
+	envVaultFound bool
+}
+
+// collect adds the fields of config to the items they are loaded from. Fields of nested structs inherit the item
+// and vault of the struct unless they specify their own.
+func (s *structFields) collect(config reflect.Value, scope fieldScope) error {
+	t := config.Type()
+	for i := 0; i < t.NumField(); i++ {
+		value := config.Field(i)
+		field := t.Field(i)
+
+		// Private members of nested structs are left alone, as they cannot be configuration values
+		if scope.prefix != "" && !value.CanSet() {
+			continue
+		}
+
+		if ref, ok := field.Tag.Lookup(referenceTag); ok {
+			if err := s.addReference(value, scope.prefix+field.Name, ref); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tag := field.Tag.Get(itemTag)
+		if tag == "" {
+			tag = scope.itemTitle
+		}
+		vaultUUIDTag := field.Tag.Get(vaultTag)
+		if vaultUUIDTag == "" {
+			vaultUUIDTag = scope.vaultUUID
+		}
+
+		if isNestedStruct(field) {
+			if !value.CanSet() {
+				continue
+			}
+			nestedScope := fieldScope{prefix: scope.prefix + field.Name + ".", itemTitle: tag, vaultUUID: vaultUUIDTag}
+			if err := s.collect(nestedStructValue(value), nestedScope); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if tag == "" {
+			continue
+		}
+
+		if !value.CanSet() {
+			return fmt.Errorf("Cannot load config into private fields")
+		}
+
+		itemVault, err := vaultUUIDForField(field.Name, vaultUUIDTag, s.envVaultUUID, s.envVaultFound)
+		if err != nil {
+			return err
+		}
+		if !isValidUUID(itemVault) {
+			return vaultUUIDError
+		}
+
+		key := fmt.Sprintf("%s/%s", itemVault, tag)
+		parsed := s.items[key]
+		parsed.vaultUUID = itemVault
+		parsed.itemTitle = tag
+		parsed.add(&field, &value, scope.prefix+field.Name)
+		s.items[key] = parsed
+	}
+	return nil
+}
+
+// addReference adds a field tagged with a secret reference to the item the reference points to.
+func (s *structFields) addReference(value reflect.Value, name string, ref string) error {
+	if !value.CanSet() {
+		return fmt.Errorf("Cannot load config into private fields")
+	}
+
+	parsed, err := ParseSecretReference(ref)
+	if err != nil {
+		return fmt.Errorf("cannot load field %s: %w", name, err)
+	}
+
+	key := fmt.Sprintf("%s/%s", parsed.Vault, parsed.Item)
+	fields, ok := s.references[key]
+	if !ok {
+		fields = &referencedFields{vault: parsed.Vault, item: parsed.Item}
+		s.references[key] = fields
+	}
+	fields.add(parsed, &value, name)
+	return nil
+}
+
+func vaultUUIDForField(fieldName string, vaultUUIDTag string, vaultUUID string, envVaultFound bool) (string, error) {
 	// Check to see if a specific vault has been specified on the field
 	// If the env vault id has not been found and item doesn't have a vault
 	// return an error
-	if vaultUUIDTag := field.Tag.Get(vaultTag); vaultUUIDTag == "" {
+	if vaultUUIDTag == "" {
 		if !envVaultFound {
-			return "", fmt.Errorf("There is no vault for %q field", field.Name)
+			return "", fmt.Errorf("There is no vault for %q field", fieldName)
 		}
 	} else {
 		return vaultUUIDTag, nil
@@ -55,88 +491,339 @@ func vaultUUIDForField(field *reflect.StructField, vaultUUID string, envVaultFou
 	return vaultUUID, nil
 }
 
-func setValuesForTag(client Client, parsedItem *parsedItem, byTitle bool) error {
+// setValuesForTag fetches the item and loads its values into the fields of parsedItem. If any fields could not be
+// loaded, a *LoadStructError listing all of them is returned.
+func setValuesForTag(ctx context.Context, client Client, parsedItem *parsedItem, byTitle bool) error {
 	var item *onepassword.Item
 	var err error
 	if byTitle {
-		item, err = client.GetItemByTitle(parsedItem.itemTitle, parsedItem.vaultUUID)
+		item, err = client.GetItemByTitleWithContext(ctx, parsedItem.itemTitle, parsedItem.vaultUUID)
 	} else {
-		item, err = client.GetItem(parsedItem.itemUUID, parsedItem.vaultUUID)
+		item, err = client.GetItemWithContext(ctx, parsedItem.itemUUID, parsedItem.vaultUUID)
 	}
 	if err != nil {
 		return err
 	}
 
-	for i, field := range parsedItem.fields {
-		value := parsedItem.values[i]
+	var errs []error
+	for i := range parsedItem.fields {
+		if err := setFieldValue(item, parsedItem, i); err != nil {
+			errs = append(errs, err)
+		}
+	}
 
-		if field.Type == reflect.TypeOf(onepassword.ItemURL{}) {
-			url := &onepassword.ItemURL{
-				Primary: urlPrimaryForName(field.Tag.Get(urlTag), item.URLs),
-				Label:   urlLabelForName(field.Tag.Get(urlTag), item.URLs),
-				URL:     urlURLForName(field.Tag.Get(urlTag), item.URLs),
-			}
-			value.Set(reflect.ValueOf(*url))
-			continue
+	if len(errs) > 0 {
+		return &LoadStructError{Errors: errs}
+	}
+	return nil
+}
+
+// setReferencedValues fetches the item the fields refer to once and resolves the reference of each field in it. A
+// reference that does not resolve is an error, also outside of strict mode.
+func setReferencedValues(ctx context.Context, client Client, fields *referencedFields) []error {
+	item, err := client.GetItemWithContext(ctx, fields.item, fields.vault)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+	for i, ref := range fields.refs {
+		if err := setReferencedValue(ctx, client, item, ref, fields.values[i]); err != nil {
+			errs = append(errs, &FieldError{Field: fields.names[i], Item: fields.item, Label: ref.String(), Err: err})
 		}
+	}
+	return errs
+}
 
-		path := fmt.Sprintf("%s.%s", field.Tag.Get(sectionTag), field.Tag.Get(fieldTag))
-		if path == "." {
-			if field.Type == reflect.TypeOf(onepassword.Item{}) {
-				value.Set(reflect.ValueOf(*item))
-				continue
-			}
-			return fmt.Errorf("There is no %q specified for %q", fieldTag, field.Name)
+func setReferencedValue(ctx context.Context, client Client, item *onepassword.Item, ref *SecretReference, value *reflect.Value) error {
+	resolved, err := resolveReferenceInItem(ctx, client, ref, item)
+	if err != nil {
+		return err
+	}
+	if isMultiValue(value.Type()) {
+		return setValues(value, splitMultiValue(resolved))
+	}
+	return setValue(value, resolved)
+}
+
+// setFieldValue loads the value of the item into the i-th field of parsedItem.
+func setFieldValue(item *onepassword.Item, parsedItem *parsedItem, i int) error {
+	field := parsedItem.fields[i]
+	value := parsedItem.values[i]
+	fieldError := func(label string, err error) error {
+		return &FieldError{Field: parsedItem.names[i], Item: parsedItem.query(), Label: label, Err: err}
+	}
+
+	fieldType := indirectType(field.Type)
+	if fieldType == reflect.TypeOf(onepassword.ItemURL{}) {
+		label := field.Tag.Get(urlTag)
+		itemURL := onepassword.ItemURL{
+			Primary: urlPrimaryForName(label, item.URLs),
+			Label:   urlLabelForName(label, item.URLs),
+			URL:     urlURLForName(label, item.URLs),
+		}
+		if itemURL.Label == "" && parsedItem.strict {
+			return fieldError(label, ErrFieldNotFound)
 		}
+		assignValue(value, reflect.ValueOf(itemURL))
+		return nil
+	}
 
-		if strings.HasSuffix(path, ".") {
-			if field.Type == reflect.TypeOf(onepassword.ItemSection{}) {
-				section := &onepassword.ItemSection{
-					ID:    sectionIDForName(field.Tag.Get(sectionTag), item.Sections),
-					Label: sectionLabelForName(field.Tag.Get(sectionTag), item.Sections),
-				}
-				value.Set(reflect.ValueOf(*section))
-				continue
+	sectionLabel := field.Tag.Get(sectionTag)
+	fieldLabel, options, err := parseFieldTag(field.Tag.Get(fieldTag))
+	if err != nil {
+		return fieldError(fieldLabel, err)
+	}
+
+	path := fmt.Sprintf("%s.%s", sectionLabel, fieldLabel)
+	if path == "." {
+		if fieldType == reflect.TypeOf(onepassword.Item{}) {
+			assignValue(value, reflect.ValueOf(*item))
+			return nil
+		}
+		return fmt.Errorf("There is no %q specified for %q", fieldTag, field.Name)
+	}
+	label := strings.TrimPrefix(path, ".")
+
+	if strings.HasSuffix(path, ".") {
+		if fieldType == reflect.TypeOf(onepassword.ItemSection{}) {
+			section := onepassword.ItemSection{
+				ID:    sectionIDForName(sectionLabel, item.Sections),
+				Label: sectionLabelForName(sectionLabel, item.Sections),
 			}
+			if section.Label == "" && parsedItem.strict {
+				return fieldError(sectionLabel, ErrFieldNotFound)
+			}
+			assignValue(value, reflect.ValueOf(section))
+			return nil
 		}
+	}
 
-		sectionID := sectionIDForName(field.Tag.Get(sectionTag), item.Sections)
+	sectionID := sectionIDForName(sectionLabel, item.Sections)
+	// Without a section of that name, the field would be looked up among the fields outside of any section
+	sectionMissing := sectionLabel != "" && sectionLabelForName(sectionLabel, item.Sections) == ""
+	isSlice := isMultiValue(value.Type())
 
-		for _, f := range item.Fields {
-			fieldSectionID := ""
-			if f.Section != nil {
-				fieldSectionID = f.Section.ID
-			}
+	var matches []string
+	for _, f := range item.Fields {
+		fieldSectionID := ""
+		if f.Section != nil {
+			fieldSectionID = f.Section.ID
+		}
 
-			if fieldSectionID == sectionID && f.Label == field.Tag.Get(fieldTag) {
-				if err := setValue(value, f.Value); err != nil {
-					return err
-				}
+		if !sectionMissing && fieldSectionID == sectionID && f.Label == fieldLabel {
+			matches = append(matches, f.Value)
+			if !isSlice {
 				break
 			}
 		}
 	}
 
+	if len(matches) == 0 {
+		switch {
+		case options.hasDefault:
+			matches = []string{options.defaultValue}
+		case (options.required || parsedItem.strict) && sectionMissing:
+			return fieldError(label, fmt.Errorf("%w: the item has no section %q", ErrFieldNotFound, sectionLabel))
+		case options.required || parsedItem.strict:
+			return fieldError(label, ErrFieldNotFound)
+		default:
+			return nil
+		}
+	}
+
+	if isSlice {
+		if len(matches) == 1 {
+			matches = splitMultiValue(matches[0])
+		}
+		err = setValues(value, matches)
+	} else {
+		err = setValue(value, matches[0])
+	}
+	if err != nil {
+		return fieldError(label, err)
+	}
 	return nil
 }
 
+// fieldOptions are the options that follow the label in an opfield tag.
+type fieldOptions struct {
+	required     bool
+	hasDefault   bool
+	defaultValue string
+}
+
+// parseFieldTag parses an opfield tag such as "port,required" or "port,default=5432" into the label of the field
+// and its options. The default option takes the rest of the tag as its value, so the default value may contain
+// commas.
+func parseFieldTag(tag string) (string, fieldOptions, error) {
+	label, rest, hasOptions := strings.Cut(tag, ",")
+	var options fieldOptions
+	for hasOptions {
+		if value, ok := strings.CutPrefix(rest, "default="); ok {
+			options.hasDefault = true
+			options.defaultValue = value
+			break
+		}
+
+		var option string
+		option, rest, hasOptions = strings.Cut(rest, ",")
+		switch option {
+		case "required":
+			options.required = true
+		default:
+			return label, options, fmt.Errorf("unknown %s option %q", fieldTag, option)
+		}
+	}
+	return label, options, nil
+}
+
+// assignValue sets value to v, or to a pointer to a copy of v if value is a pointer.
+func assignValue(value *reflect.Value, v reflect.Value) {
+	if value.Kind() == reflect.Ptr {
+		ptr := reflect.New(v.Type())
+		ptr.Elem().Set(v)
+		value.Set(ptr)
+		return
+	}
+	value.Set(v)
+}
+
+// splitMultiValue splits the value of a field holding several values separated by commas or newlines.
+func splitMultiValue(value string) []string {
+	var values []string
+	for _, v := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '\n' }) {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// setValues sets the slice value to the given values, converted to the type of its elements.
+func setValues(value *reflect.Value, toSet []string) error {
+	slice := reflect.MakeSlice(value.Type(), len(toSet), len(toSet))
+	for i, v := range toSet {
+		elem := slice.Index(i)
+		if err := setValue(&elem, v); err != nil {
+			return err
+		}
+	}
+	value.Set(slice)
+	return nil
+}
+
+// isMultiValue reports whether values of type t are loaded from several fields of an item.
+func isMultiValue(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() != reflect.Uint8 && !isTextUnmarshaler(t)
+}
+
+func isTextUnmarshaler(t reflect.Type) bool {
+	return reflect.PtrTo(t).Implements(textUnmarshalerType)
+}
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+	urlType             = reflect.TypeOf(url.URL{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// setValue sets value to the value of an item field, converted to the type of value.
 func setValue(value *reflect.Value, toSet string) error {
+	if value.Kind() == reflect.Ptr {
+		ptr := reflect.New(value.Type().Elem())
+		elem := ptr.Elem()
+		if err := setValue(&elem, toSet); err != nil {
+			return err
+		}
+		value.Set(ptr)
+		return nil
+	}
+
+	switch value.Type() {
+	case durationType:
+		d, err := time.ParseDuration(toSet)
+		if err != nil {
+			return err
+		}
+		value.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := parseDate(toSet)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(t))
+		return nil
+	case urlType:
+		u, err := url.Parse(toSet)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(*u))
+		return nil
+	}
+
+	if value.CanAddr() && isTextUnmarshaler(value.Type()) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(toSet))
+	}
+
 	switch value.Kind() {
 	case reflect.String:
 		value.SetString(toSet)
-	case reflect.Int:
-		v, err := strconv.Atoi(toSet)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(toSet)
 		if err != nil {
 			return err
 		}
-		value.SetInt(int64(v))
+		value.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(toSet, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(toSet, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(toSet, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(v)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", value.Type())
+		}
+		value.SetBytes([]byte(toSet))
 	default:
-		return fmt.Errorf("Unsupported type %q. Only string, int64, and onepassword.Item are supported", value.Kind())
+		return fmt.Errorf("unsupported type %s", value.Type())
 	}
 
 	return nil
 }
 
+// parseDate parses the value of a DATE field, which Connect returns as a date such as 2006-01-02. Timestamps in
+// the RFC 3339 format and Unix timestamps in seconds are accepted as well.
+func parseDate(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q as a date", value)
+	}
+	return t, nil
+}
+
 func sectionIDForName(name string, sections []*onepassword.ItemSection) string {
 	if sections == nil {
 		return ""
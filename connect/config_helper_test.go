@@ -0,0 +1,507 @@
+package connect
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+// itemWithFields returns an item in the test vault with the given fields, none of which are in a section.
+func itemWithFields(fields ...*onepassword.ItemField) onepassword.Item {
+	return onepassword.Item{
+		ID:     testItemUUID,
+		Title:  "test-item",
+		Vault:  onepassword.ItemVault{ID: testVaultUUID},
+		Fields: fields,
+	}
+}
+
+func TestLoadStructFromItemNestedStructs(t *testing.T) {
+	type Credentials struct {
+		Username string `opfield:"username"`
+	}
+	type testConfig struct {
+		Credentials
+		DB struct {
+			Password string `opsection:"section" opfield:"password"`
+		}
+		Optional *struct {
+			URL onepassword.ItemURL `opurl:"url"`
+		}
+	}
+	mockHTTPClient.Dofunc = getComplexItem
+
+	c := testConfig{}
+	err := testClient.LoadStructFromItemByUUID(&c, testItemUUID, testVaultUUID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "wendy", c.Username)
+	assert.Equal(t, "appleseed", c.DB.Password)
+	if assert.NotNil(t, c.Optional) {
+		assert.Equal(t, "https://www.appleseed.com", c.Optional.URL.URL)
+	}
+}
+
+func TestLoadStructFromItemPointers(t *testing.T) {
+	type testConfig struct {
+		Username *string                  `opfield:"username"`
+		Missing  *string                  `opfield:"missing"`
+		Section  *onepassword.ItemSection `opsection:"section"`
+		Item     *onepassword.Item
+	}
+	mockHTTPClient.Dofunc = getComplexItem
+
+	c := testConfig{}
+	err := testClient.LoadStructFromItemByUUID(&c, testItemUUID, testVaultUUID)
+
+	assert.Nil(t, err)
+	if assert.NotNil(t, c.Username) {
+		assert.Equal(t, "wendy", *c.Username)
+	}
+	assert.Nil(t, c.Missing)
+	if assert.NotNil(t, c.Section) {
+		assert.Equal(t, "section", c.Section.Label)
+	}
+	if assert.NotNil(t, c.Item) {
+		assert.Equal(t, "test-item", c.Item.Title)
+	}
+}
+
+func TestLoadStructFromItemSlices(t *testing.T) {
+	type testConfig struct {
+		Hosts   []string `opfield:"hosts"`
+		Ports   []int    `opfield:"port"`
+		Missing []string `opfield:"missing"`
+	}
+	mockHTTPClient.Dofunc = respondJSON(itemWithFields(
+		&onepassword.ItemField{Label: "hosts", Value: "db1.internal, db2.internal\ndb3.internal"},
+		&onepassword.ItemField{Label: "port", Value: "5432"},
+		&onepassword.ItemField{Label: "port", Value: "5433"},
+	))
+
+	c := testConfig{}
+	err := testClient.LoadStructFromItemByUUID(&c, testItemUUID, testVaultUUID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"db1.internal", "db2.internal", "db3.internal"}, c.Hosts)
+	assert.Equal(t, []int{5432, 5433}, c.Ports)
+	assert.Nil(t, c.Missing)
+}
+
+func TestLoadStructNestedStructInheritsItem(t *testing.T) {
+	type testConfig struct {
+		DB struct {
+			Username string `opfield:"username"`
+			Password string `opsection:"section" opfield:"password"`
+		} `opvault:"5b52aa139ef74d7ca17918nmf8" opitem:"test-item"`
+		Other struct {
+			Username string `opitem:"test-item" opfield:"username"`
+		}
+		Untagged string
+	}
+	mock := newCountingMock(listItemsOrGetItem)
+	client := NewClient(validHost, validToken, WithHTTPClient(mock))
+
+	c := testConfig{}
+	err := client.LoadStruct(&c)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "wendy", c.DB.Username)
+	assert.Equal(t, "appleseed", c.DB.Password)
+	assert.Equal(t, "wendy", c.Other.Username, "fields without a vault should use the OP_VAULT vault")
+	assert.Empty(t, c.Untagged)
+	assert.Equal(t, 4, mock.total(), "expected the item to be fetched once per vault")
+}
+
+func TestLoadStructSkipsUntaggedStructs(t *testing.T) {
+	type withPrivate struct {
+		Name   string
+		parent *withPrivate
+	}
+	type testConfig struct {
+		Username string `opitem:"test-item" opfield:"username"`
+		Client   *http.Client
+		Private  withPrivate
+		Timeout  struct{ Read time.Duration }
+	}
+	mockHTTPClient.Dofunc = listItemsOrGetItem
+
+	c := testConfig{}
+	err := testClient.LoadStruct(&c)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "wendy", c.Username)
+	assert.Nil(t, c.Client, "structs without tagged fields should not be allocated")
+}
+
+func TestLoadStructSkipsPrivateMembersOfNestedStructs(t *testing.T) {
+	type database struct {
+		Username string `opfield:"username"`
+		client   *http.Client
+		password string
+	}
+	type testConfig struct {
+		DB *database `opitem:"test-item"`
+	}
+	mockHTTPClient.Dofunc = listItemsOrGetItem
+
+	c := testConfig{}
+	err := testClient.LoadStruct(&c)
+
+	assert.Nil(t, err)
+	if assert.NotNil(t, c.DB) {
+		assert.Equal(t, "wendy", c.DB.Username)
+		assert.Nil(t, c.DB.client)
+		assert.Empty(t, c.DB.password)
+	}
+}
+
+// level implements encoding.TextUnmarshaler.
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return fmt.Errorf("unknown level %q", text)
+	}
+	return nil
+}
+
+func TestSetValue(t *testing.T) {
+	cases := map[string]struct {
+		value     string
+		expected  interface{}
+		expectErr bool
+	}{
+		"string":         {value: "text", expected: "text"},
+		"bool":           {value: "true", expected: true},
+		"int":            {value: "-42", expected: -42},
+		"int8":           {value: "-8", expected: int8(-8)},
+		"int64":          {value: "9000000000", expected: int64(9000000000)},
+		"uint16":         {value: "65535", expected: uint16(65535)},
+		"uint64":         {value: "42", expected: uint64(42)},
+		"float32":        {value: "1.5", expected: float32(1.5)},
+		"float64":        {value: "0.25", expected: 0.25},
+		"duration":       {value: "1m30s", expected: 90 * time.Second},
+		"date":           {value: "2023-05-17", expected: time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC)},
+		"unix date":      {value: "1684281600", expected: time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC)},
+		"url":            {value: "https://example.com/path", expected: url.URL{Scheme: "https", Host: "example.com", Path: "/path"}},
+		"url pointer":    {value: "https://example.com", expected: &url.URL{Scheme: "https", Host: "example.com"}},
+		"bytes":          {value: "secret", expected: []byte("secret")},
+		"text unmarshal": {value: "high", expected: level(2)},
+		"string pointer": {value: "text", expected: stringPointer("text")},
+		"ip address":     {value: "10.0.0.1", expected: net.ParseIP("10.0.0.1")},
+		"time pointer":   {value: "2023-05-17", expected: timePointer(time.Date(2023, 5, 17, 0, 0, 0, 0, time.UTC))},
+		"negative uint":  {value: "-1", expected: uint16(0), expectErr: true},
+		"invalid bool":   {value: "maybe", expected: false, expectErr: true},
+		"invalid date":   {value: "17/05/2023", expected: time.Time{}, expectErr: true},
+		"unsupported":    {value: "1", expected: complex64(0), expectErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			value := reflect.New(reflect.TypeOf(tc.expected)).Elem()
+			err := setValue(&value, tc.value)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, value.Interface())
+		})
+	}
+}
+
+func TestLoadStructFromItemConversionError(t *testing.T) {
+	type testConfig struct {
+		DB struct {
+			Port int `opsection:"database" opfield:"port"`
+		}
+	}
+	item := itemWithFields(&onepassword.ItemField{
+		Label:   "port",
+		Value:   "not-a-number",
+		Section: &onepassword.ItemSection{ID: "database-id"},
+	})
+	item.Sections = []*onepassword.ItemSection{{ID: "database-id", Label: "database"}}
+	mockHTTPClient.Dofunc = respondJSON(item)
+
+	c := testConfig{}
+	err := testClient.LoadStructFromItemByUUID(&c, testItemUUID, testVaultUUID)
+
+	var fieldErr *FieldError
+	if assert.ErrorAs(t, err, &fieldErr) {
+		assert.Equal(t, "DB.Port", fieldErr.Field)
+		assert.Equal(t, testItemUUID, fieldErr.Item)
+		assert.Equal(t, "database.port", fieldErr.Label)
+	}
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+	assert.Contains(t, err.Error(), `"database.port"`)
+	assert.Contains(t, err.Error(), "DB.Port")
+}
+
+func stringPointer(s string) *string {
+	return &s
+}
+
+func timePointer(t time.Time) *time.Time {
+	return &t
+}
+
+func TestParseFieldTag(t *testing.T) {
+	cases := map[string]struct {
+		tag             string
+		expectedLabel   string
+		expectedOptions fieldOptions
+		expectErr       bool
+	}{
+		"label only": {
+			tag:           "password",
+			expectedLabel: "password",
+		},
+		"required": {
+			tag:             "password,required",
+			expectedLabel:   "password",
+			expectedOptions: fieldOptions{required: true},
+		},
+		"default": {
+			tag:             "port,default=5432",
+			expectedLabel:   "port",
+			expectedOptions: fieldOptions{hasDefault: true, defaultValue: "5432"},
+		},
+		"default with commas": {
+			tag:             "hosts,required,default=db1,db2",
+			expectedLabel:   "hosts",
+			expectedOptions: fieldOptions{required: true, hasDefault: true, defaultValue: "db1,db2"},
+		},
+		"empty default": {
+			tag:             "suffix,default=",
+			expectedLabel:   "suffix",
+			expectedOptions: fieldOptions{hasDefault: true},
+		},
+		"unknown option": {
+			tag:       "password,optional",
+			expectErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			label, options, err := parseFieldTag(tc.tag)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedLabel, label)
+			assert.Equal(t, tc.expectedOptions, options)
+		})
+	}
+}
+
+func TestLoadStructFromItemRequiredAndDefault(t *testing.T) {
+	type testConfig struct {
+		Username string   `opfield:"username,required"`
+		Port     int      `opfield:"port,default=5432"`
+		Hosts    []string `opfield:"hosts,default=db1, db2"`
+		User     string   `opfield:"username,default=admin"`
+		Optional string   `opfield:"optional"`
+	}
+	mockHTTPClient.Dofunc = getComplexItem
+
+	c := testConfig{}
+	err := testClient.LoadStructFromItemByUUID(&c, testItemUUID, testVaultUUID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "wendy", c.Username)
+	assert.Equal(t, 5432, c.Port)
+	assert.Equal(t, []string{"db1", "db2"}, c.Hosts)
+	assert.Equal(t, "wendy", c.User, "the value of the item should take precedence over the default")
+	assert.Empty(t, c.Optional)
+}
+
+func TestLoadStructFromItemRequiredMissing(t *testing.T) {
+	type testConfig struct {
+		DB struct {
+			Password string `opfield:"db-password,required"`
+			Port     int    `opfield:"port"`
+		}
+	}
+	mockHTTPClient.Dofunc = getComplexItem
+
+	c := testConfig{}
+	err := testClient.LoadStructFromItemByUUID(&c, testItemUUID, testVaultUUID)
+
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+	var fieldErr *FieldError
+	if assert.ErrorAs(t, err, &fieldErr) {
+		assert.Equal(t, "DB.Password", fieldErr.Field)
+		assert.Equal(t, "db-password", fieldErr.Label)
+	}
+}
+
+func TestLoadStructStrictAggregatesErrors(t *testing.T) {
+	type testConfig struct {
+		Username string              `opitem:"test-item" opfield:"username"`
+		Password string              `opitem:"test-item" opfield:"db-password"`
+		Port     int                 `opitem:"test-item" opfield:"port,default=5432"`
+		Token    string              `opvault:"5b52aa139ef74d7ca17918nmf8" opitem:"test-item" opfield:"token"`
+		Site     onepassword.ItemURL `opitem:"test-item" opurl:"website"`
+	}
+	client := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: listItemsOrGetItem}), WithStrictLoadStruct())
+
+	c := testConfig{}
+	err := client.LoadStruct(&c)
+
+	var loadErr *LoadStructError
+	if assert.ErrorAs(t, err, &loadErr) && assert.Len(t, loadErr.Errors, 3) {
+		var labels []string
+		for _, err := range loadErr.Errors {
+			var fieldErr *FieldError
+			if assert.ErrorAs(t, err, &fieldErr) {
+				labels = append(labels, fieldErr.Label)
+			}
+		}
+		assert.Equal(t, []string{"token", "db-password", "website"}, labels)
+	}
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+	assert.Equal(t, "wendy", c.Username, "fields that could be loaded should still be set")
+	assert.Equal(t, 5432, c.Port)
+}
+
+func TestLoadStructMissingSection(t *testing.T) {
+	type testConfig struct {
+		Username string `opitem:"test-item" opsection:"no-such-section" opfield:"username"`
+	}
+
+	// The field outside of any section with the same label is not loaded instead
+	mockHTTPClient.Dofunc = listItemsOrGetItem
+	c := testConfig{}
+	err := testClient.LoadStruct(&c)
+	assert.Nil(t, err)
+	assert.Empty(t, c.Username)
+
+	client := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: listItemsOrGetItem}), WithStrictLoadStruct())
+	err = client.LoadStruct(&c)
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+	assert.ErrorContains(t, err, `no section "no-such-section"`)
+	assert.Empty(t, c.Username)
+}
+
+// wrappingClient is a Client that wraps another one and passes on its loading mode.
+type wrappingClient struct {
+	Client
+}
+
+func (w wrappingClient) StrictLoadStruct() bool {
+	return w.Client.(StrictLoader).StrictLoadStruct()
+}
+
+func TestLoadStructStrictThroughWrappingClients(t *testing.T) {
+	type testConfig struct {
+		Password string `opitem:"test-item" opfield:"db-password"`
+	}
+	strict := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: listItemsOrGetItem}), WithStrictLoadStruct())
+	client, err := NewCachingClient(wrappingClient{strict})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	c := testConfig{}
+	err = client.LoadStruct(&c)
+
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+}
+
+func TestLoadStructWithoutStrictIgnoresMissingFields(t *testing.T) {
+	type testConfig struct {
+		Username string `opitem:"test-item" opfield:"username"`
+		Password string `opitem:"test-item" opfield:"db-password"`
+	}
+	mockHTTPClient.Dofunc = listItemsOrGetItem
+
+	c := testConfig{}
+	err := testClient.LoadStruct(&c)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "wendy", c.Username)
+	assert.Empty(t, c.Password)
+}
+
+func TestLoadStructSecretReferences(t *testing.T) {
+	type database struct {
+		Password string `op:"op://5b52aa139ef74d7ca17918nmf8/2a47aa139ef74d7ca17918035e/database/password"`
+	}
+	type testConfig struct {
+		Username string `op:"op://5b52aa139ef74d7ca17918nmf8/2a47aa139ef74d7ca17918035e/username"`
+		OTP      string `op:"op://5b52aa139ef74d7ca17918nmf8/2a47aa139ef74d7ca17918035e/one-time password?attribute=otp"`
+		DB       database
+		Legacy   string `opitem:"test-item" opfield:"username"`
+	}
+	mock := newCountingMock(serveReferencedItem)
+	client := NewClient(validHost, validToken, WithHTTPClient(mock))
+
+	c := testConfig{}
+	err := client.LoadStruct(&c)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "wendy", c.Username)
+	assert.Equal(t, "123456", c.OTP)
+	assert.Equal(t, "db-secret", c.DB.Password)
+	assert.Equal(t, "wendy", c.Legacy)
+	assert.Equal(t, 1, mock.count(http.MethodGet, fmt.Sprintf("/v1/vaults/%s/items/%s", testVaultUUID, testItemUUID)),
+		"fields referring to the same item should share a request")
+}
+
+func TestLoadStructSecretReferenceSectionMatchesLegacyTags(t *testing.T) {
+	type testConfig struct {
+		Legacy    string `opitem:"test-item" opsection:"database" opfield:"password"`
+		Reference string `op:"op://otl6r6nugj5wr63rnkw3v4pbna/test-item/Database/password"`
+	}
+	mockHTTPClient.Dofunc = serveReferencedItem
+
+	c := testConfig{}
+	err := testClient.LoadStruct(&c)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "db-secret", c.Legacy)
+	assert.Equal(t, c.Legacy, c.Reference, "both tag styles should resolve the same section")
+}
+
+func TestLoadStructSecretReferenceErrors(t *testing.T) {
+	mockHTTPClient.Dofunc = serveReferencedItem
+
+	invalid := struct {
+		Password string `op:"op://5b52aa139ef74d7ca17918nmf8/password"`
+	}{}
+	err := testClient.LoadStruct(&invalid)
+	assert.ErrorIs(t, err, ErrInvalidReference)
+
+	missing := struct {
+		Username string `op:"op://5b52aa139ef74d7ca17918nmf8/2a47aa139ef74d7ca17918035e/username"`
+		Token    string `op:"op://5b52aa139ef74d7ca17918nmf8/2a47aa139ef74d7ca17918035e/token"`
+	}{}
+	err = testClient.LoadStruct(&missing)
+	assert.ErrorIs(t, err, ErrFieldNotFound, "references should resolve also outside of strict mode")
+	var fieldErr *FieldError
+	if assert.ErrorAs(t, err, &fieldErr) {
+		assert.Equal(t, "Token", fieldErr.Field)
+		assert.Equal(t, testItemUUID, fieldErr.Item)
+		assert.Equal(t, "op://5b52aa139ef74d7ca17918nmf8/2a47aa139ef74d7ca17918035e/token", fieldErr.Label)
+	}
+	assert.Equal(t, "wendy", missing.Username)
+}
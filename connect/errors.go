@@ -0,0 +1,131 @@
+package connect
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+// Errors returned by the Client methods can be matched against these errors with errors.Is. If the error was
+// returned by the Connect API, the *onepassword.Error can be retrieved with errors.As.
+var (
+	// ErrItemNotFound is matched if no item has the given ID or title.
+	ErrItemNotFound = errors.New("item not found")
+	// ErrVaultNotFound is matched if no vault has the given ID or title.
+	ErrVaultNotFound = errors.New("vault not found")
+	// ErrAmbiguousTitle is matched if more than one vault or item has the given title. The IDs of all of them can be
+	// retrieved by using errors.As with an *AmbiguousTitleError.
+	ErrAmbiguousTitle = errors.New("ambiguous title")
+	// ErrUnauthorized is matched if Connect rejected the token, or the token has no access to the vault.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrInvalidID is matched if a malformed vault, item or file ID was provided.
+	ErrInvalidID = errors.New("invalid ID")
+)
+
+var (
+	vaultPathPattern = regexp.MustCompile(`^/v1/vaults/[^/]+(/items)?$`)
+	itemPathPattern  = regexp.MustCompile(`^/v1/vaults/[^/]+/items/[^/]+$`)
+)
+
+// AmbiguousTitleError is returned if a vault or an item is looked up by its title, and more than one has that title.
+type AmbiguousTitleError struct {
+	Title string
+	// VaultID is the vault that was searched for items with the title, or empty if vaults were searched.
+	VaultID string
+	// IDs are the IDs of all vaults or items with the title.
+	IDs []string
+}
+
+func (e *AmbiguousTitleError) Error() string {
+	if e.VaultID == "" {
+		return fmt.Sprintf("Found %d vaults with title %q", len(e.IDs), e.Title)
+	}
+	return fmt.Sprintf("Found %d item(s) in vault %q with title %q", len(e.IDs), e.VaultID, e.Title)
+}
+
+func (e *AmbiguousTitleError) Is(target error) bool {
+	return target == ErrAmbiguousTitle
+}
+
+// classifiedError is an error that matches one of the exported errors, in addition to the underlying error.
+type classifiedError struct {
+	class error
+	err   error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() []error {
+	return []error{e.class, e.err}
+}
+
+// classifyAPIError makes the error returned by Connect in response to the given request match the exported error
+// that describes it, if any.
+func classifyAPIError(request *http.Request, statusCode int, apiErr *onepassword.Error) error {
+	switch statusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &classifiedError{class: ErrUnauthorized, err: apiErr}
+	case http.StatusNotFound:
+		if request == nil {
+			break
+		}
+		if itemPathPattern.MatchString(request.URL.Path) {
+			return &classifiedError{class: ErrItemNotFound, err: apiErr}
+		}
+		if vaultPathPattern.MatchString(request.URL.Path) {
+			return &classifiedError{class: ErrVaultNotFound, err: apiErr}
+		}
+	}
+	return apiErr
+}
+
+// ErrVersionConflict is matched by errors.Is if an item could not be updated because it was modified since it was
+// read. The versions involved can be retrieved by using errors.As with a *VersionConflictError.
+var ErrVersionConflict = errors.New("item version conflict")
+
+// VersionConflictError is returned by UpdateItemChecked if the version of the item in Connect does not match the
+// version of the item that was passed in.
+type VersionConflictError struct {
+	ItemID string
+	// ExpectedVersion is the version of the item that was passed in.
+	ExpectedVersion int
+	// CurrentVersion is the version of the item in Connect, or 0 if it could not be retrieved.
+	CurrentVersion int
+}
+
+func (e *VersionConflictError) Error() string {
+	if e.CurrentVersion == 0 {
+		return fmt.Sprintf("item %s was modified after version %d", e.ItemID, e.ExpectedVersion)
+	}
+	return fmt.Sprintf("item %s was modified: expected version %d, found version %d", e.ItemID, e.ExpectedVersion, e.CurrentVersion)
+}
+
+func (e *VersionConflictError) Is(target error) bool {
+	return target == ErrVersionConflict
+}
+
+// ErrUnsupportedServerVersion is matched by errors.Is if a method cannot be used because the Connect server is too
+// old. The versions involved can be retrieved by using errors.As with a *UnsupportedServerVersionError.
+var ErrUnsupportedServerVersion = errors.New("unsupported Connect server version")
+
+// UnsupportedServerVersionError is returned if the Connect server does not support a capability that a method needs.
+type UnsupportedServerVersionError struct {
+	Capability Capability
+	// Required is the first version of Connect that supports the capability.
+	Required ServerVersion
+	// Detected is the version of the Connect server.
+	Detected ServerVersion
+}
+
+func (e *UnsupportedServerVersionError) Error() string {
+	return fmt.Sprintf("need at least version %s of Connect for %s, detected version %s. Please update your Connect server", e.Required, e.Capability, e.Detected)
+}
+
+func (e *UnsupportedServerVersionError) Is(target error) bool {
+	return target == ErrUnsupportedServerVersion
+}
@@ -0,0 +1,130 @@
+package connect
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+// respondJSON responds to every request with the given value.
+func respondJSON(v interface{}) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		body, _ := json.Marshal(v)
+		return &http.Response{
+			Status:     http.StatusText(http.StatusOK),
+			StatusCode: http.StatusOK,
+			Body:       ioutil.NopCloser(bytes.NewReader(body)),
+			Header:     http.Header{},
+		}, nil
+	}
+}
+
+func TestErrItemNotFound(t *testing.T) {
+	errResult := apiError(http.StatusNotFound, "Item not found")
+	mockHTTPClient.Dofunc = respondError(errResult)
+
+	_, err := testClient.GetItemByUUID(testItemUUID, testVaultUUID)
+
+	assert.ErrorIs(t, err, ErrItemNotFound)
+	assert.ErrorIs(t, err, errResult)
+	assert.NotErrorIs(t, err, ErrVaultNotFound)
+	var apiErr *onepassword.Error
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	}
+	assert.EqualError(t, err, errResult.Error())
+}
+
+func TestErrItemNotFoundByTitle(t *testing.T) {
+	mockHTTPClient.Dofunc = respondJSON([]onepassword.Item{})
+
+	_, err := testClient.GetItem("test-item", testVaultUUID)
+
+	assert.ErrorIs(t, err, ErrItemNotFound)
+	assert.EqualError(t, err, `Found 0 item(s) in vault "`+testVaultUUID+`" with title "test-item"`)
+}
+
+func TestErrVaultNotFound(t *testing.T) {
+	mockHTTPClient.Dofunc = respondError(apiError(http.StatusNotFound, "Vault not found"))
+
+	_, err := testClient.GetVaultByUUID(testVaultUUID)
+	assert.ErrorIs(t, err, ErrVaultNotFound)
+
+	_, err = testClient.GetItems(testVaultUUID)
+	assert.ErrorIs(t, err, ErrVaultNotFound)
+
+	mockHTTPClient.Dofunc = respondJSON([]onepassword.Vault{})
+	_, err = testClient.GetItems("Missing vault")
+	assert.ErrorIs(t, err, ErrVaultNotFound)
+}
+
+func TestErrAmbiguousTitle(t *testing.T) {
+	mockHTTPClient.Dofunc = respondJSON([]onepassword.Vault{{ID: "vault1"}, {ID: "vault2"}})
+
+	_, err := testClient.GetVaultByTitle("Shared")
+
+	assert.ErrorIs(t, err, ErrAmbiguousTitle)
+	var ambiguous *AmbiguousTitleError
+	if assert.ErrorAs(t, err, &ambiguous) {
+		assert.Equal(t, []string{"vault1", "vault2"}, ambiguous.IDs)
+	}
+	assert.EqualError(t, err, `Found 2 vaults with title "Shared"`)
+}
+
+func TestErrAmbiguousItemTitle(t *testing.T) {
+	const otherItemUUID = "3b58bb240fa85e8db28a29146f"
+	mockHTTPClient.Dofunc = func(req *http.Request) (*http.Response, error) {
+		itemsPath := "/v1/vaults/" + testVaultUUID + "/items"
+		if req.URL.Path == itemsPath {
+			return respondJSON([]onepassword.Item{
+				{ID: testItemUUID, Vault: onepassword.ItemVault{ID: testVaultUUID}},
+				{ID: otherItemUUID, Vault: onepassword.ItemVault{ID: testVaultUUID}},
+			})(req)
+		}
+		return respondJSON(onepassword.Item{
+			ID:    strings.TrimPrefix(req.URL.Path, itemsPath+"/"),
+			Vault: onepassword.ItemVault{ID: testVaultUUID},
+		})(req)
+	}
+
+	err := testClient.DeleteItemByTitle("Duplicate", testVaultUUID)
+
+	var ambiguous *AmbiguousTitleError
+	if assert.ErrorAs(t, err, &ambiguous) {
+		assert.Equal(t, &AmbiguousTitleError{Title: "Duplicate", VaultID: testVaultUUID, IDs: []string{testItemUUID, otherItemUUID}}, ambiguous)
+	}
+}
+
+func TestErrUnauthorized(t *testing.T) {
+	errResult := apiError(http.StatusUnauthorized, "Invalid token signature")
+	mockHTTPClient.Dofunc = respondError(errResult)
+
+	_, err := testClient.GetVaults()
+	assert.ErrorIs(t, err, ErrUnauthorized)
+	assert.ErrorIs(t, err, errResult)
+
+	cache, err := NewCachingClient(testClient)
+	assert.Nil(t, err)
+	defer cache.Close()
+	_, err = cache.GetItem(testItemUUID, testVaultUUID)
+	assert.ErrorIs(t, err, ErrUnauthorized)
+}
+
+func TestErrInvalidID(t *testing.T) {
+	_, err := testClient.GetItemByUUID("not-an-id", testVaultUUID)
+	assert.ErrorIs(t, err, ErrInvalidID)
+	assert.EqualError(t, err, "malformed item uuid provided")
+
+	_, err = testClient.GetVaultByUUID("not-an-id")
+	assert.ErrorIs(t, err, ErrInvalidID)
+
+	_, err = testClient.GetFile("not-an-id", testItemUUID, testVaultUUID)
+	assert.ErrorIs(t, err, ErrInvalidID)
+}
@@ -0,0 +1,180 @@
+package connect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// redacted replaces secrets in logs.
+const redacted = "[REDACTED]"
+
+// logRequest logs a request that was sent to Connect at debug level. Only metadata of the request is logged,
+// never the token or the contents of items and files.
+func (rs *restClient) logRequest(request *http.Request, statusCode int, retries int, duration time.Duration, err error) {
+	if rs.logger == nil {
+		return
+	}
+	ctx := request.Context()
+	if !rs.logger.Enabled(ctx, slog.LevelDebug) {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("operation", OperationName(ctx)),
+		slog.String("method", request.Method),
+		slog.String("path", request.URL.Path),
+	}
+	if match := resourcePathPattern.FindStringSubmatch(request.URL.Path); match != nil {
+		attrs = append(attrs, slog.String("vault_id", match[1]))
+		if match[2] != "" {
+			attrs = append(attrs, slog.String("item_id", match[2]))
+		}
+	}
+	if statusCode != 0 {
+		attrs = append(attrs, slog.Int("status", statusCode))
+	}
+	attrs = append(attrs,
+		slog.Duration("duration", duration),
+		slog.Int("retries", retries),
+	)
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	rs.logger.LogAttrs(ctx, slog.LevelDebug, "connect request", attrs...)
+}
+
+// dumpClient is an HTTPClient that logs every request and response it sends and receives at debug level, with
+// the Authorization header and the values of fields redacted.
+type dumpClient struct {
+	client HTTPClient
+	logger *slog.Logger
+}
+
+func (c dumpClient) Do(request *http.Request) (*http.Response, error) {
+	ctx := request.Context()
+	if !c.logger.Enabled(ctx, slog.LevelDebug) {
+		return c.client.Do(request)
+	}
+
+	body, err := readAndRestoreBody(&request.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "connect request dump",
+		slog.String("operation", OperationName(ctx)),
+		slog.String("request", dumpMessage(fmt.Sprintf("%s %s", request.Method, request.URL.RequestURI()), request.Header, body)),
+	)
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err = readAndRestoreBody(&response.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.LogAttrs(ctx, slog.LevelDebug, "connect response dump",
+		slog.String("operation", OperationName(ctx)),
+		slog.String("response", dumpMessage(response.Status, response.Header, body)),
+	)
+	return response, nil
+}
+
+// readAndRestoreBody reads the body and replaces it with a reader over the read bytes.
+func readAndRestoreBody(body *io.ReadCloser) ([]byte, error) {
+	if *body == nil || *body == http.NoBody {
+		return nil, nil
+	}
+	content, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return nil, err
+	}
+	*body = io.NopCloser(bytes.NewReader(content))
+	return content, nil
+}
+
+// dumpMessage formats an HTTP message for logging, redacting the Authorization header and secrets in the body.
+func dumpMessage(firstLine string, header http.Header, body []byte) string {
+	var dump strings.Builder
+	dump.WriteString(firstLine)
+	dump.WriteString("\n")
+
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			if http.CanonicalHeaderKey(key) == "Authorization" {
+				value = redacted
+			}
+			fmt.Fprintf(&dump, "%s: %s\n", key, value)
+		}
+	}
+
+	if len(body) > 0 {
+		dump.WriteString("\n")
+		dump.WriteString(redactBody(body))
+	}
+	return dump.String()
+}
+
+// loggedKeys are the JSON properties of the Connect API that hold metadata rather than secrets. The values of
+// all other properties, such as the value and one-time password of a field or the content of a file, are redacted,
+// so that properties added to the API later are redacted until they are known to be safe.
+var loggedKeys = map[string]bool{
+	"account": true, "action": true, "actor": true, "attributeVersion": true, "category": true,
+	"characterSets": true, "content_path": true, "contentVersion": true, "createdAt": true, "dependencies": true,
+	"description": true, "entropy": true, "favorite": true, "fields": true, "files": true, "generate": true,
+	"href": true, "id": true, "item": true, "items": true, "itemVersion": true, "jti": true, "label": true,
+	"lastEditedBy": true, "length": true, "message": true, "name": true, "op": true, "path": true, "primary": true,
+	"purpose": true, "recipe": true, "requestId": true, "requestIp": true, "resource": true, "result": true,
+	"section": true, "sections": true, "service": true, "size": true, "status": true, "tags": true,
+	"timestamp": true, "title": true, "trashed": true, "type": true, "updatedAt": true, "urls": true,
+	"userAgent": true, "vault": true, "version": true,
+}
+
+// redactBody returns the body with the values of all properties that are not known to hold metadata redacted,
+// which includes the values of fields and of patch operations. A body that is not JSON, e.g. the content of a file,
+// is left out entirely.
+func redactBody(body []byte) string {
+	var content interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&content); err != nil {
+		return fmt.Sprintf("[%d bytes of non-JSON content]", len(body))
+	}
+	out, err := json.Marshal(redactValues(content))
+	if err != nil {
+		return fmt.Sprintf("[%d bytes of content]", len(body))
+	}
+	return string(out)
+}
+
+func redactValues(content interface{}) interface{} {
+	switch content := content.(type) {
+	case map[string]interface{}:
+		for key, value := range content {
+			if loggedKeys[key] {
+				content[key] = redactValues(value)
+			} else {
+				content[key] = redacted
+			}
+		}
+	case []interface{}:
+		for i, value := range content {
+			content[i] = redactValues(value)
+		}
+	}
+	return content
+}
@@ -0,0 +1,148 @@
+package connect
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestLogger(level slog.Level) (*slog.Logger, *bytes.Buffer) {
+	var output bytes.Buffer
+	return slog.New(slog.NewJSONHandler(&output, &slog.HandlerOptions{Level: level})), &output
+}
+
+// logRecords decodes the records written by a logger created with newTestLogger.
+func logRecords(t *testing.T, output *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	decoder := json.NewDecoder(output)
+	for decoder.More() {
+		var record map[string]interface{}
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestWithLogger(t *testing.T) {
+	logger, output := newTestLogger(slog.LevelDebug)
+	client := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: getItem}), WithLogger(logger))
+
+	_, err := client.GetItemByUUID(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	assert.NotContains(t, output.String(), validToken)
+	assert.NotContains(t, output.String(), "wendy")
+	records := logRecords(t, output)
+	if !assert.Len(t, records, 1) {
+		return
+	}
+	record := records[0]
+	assert.Equal(t, "DEBUG", record["level"])
+	assert.Equal(t, "connect request", record["msg"])
+	assert.Equal(t, "GetItemByUUID", record["operation"])
+	assert.Equal(t, http.MethodGet, record["method"])
+	assert.Equal(t, "/v1/vaults/"+testVaultUUID+"/items/"+testItemUUID, record["path"])
+	assert.Equal(t, testVaultUUID, record["vault_id"])
+	assert.Equal(t, testItemUUID, record["item_id"])
+	assert.Equal(t, float64(http.StatusOK), record["status"])
+	assert.Equal(t, float64(0), record["retries"])
+	assert.Contains(t, record, "duration")
+}
+
+func TestWithLoggerRetriesAndErrors(t *testing.T) {
+	logger, output := newTestLogger(slog.LevelDebug)
+	client := NewClient(validHost, validToken,
+		WithHTTPClient(&mockClient{Dofunc: respondError(apiError(http.StatusServiceUnavailable, "unavailable"))}),
+		WithRetryPolicy(testRetryPolicy),
+		WithLogger(logger),
+	)
+
+	_, err := client.GetVaults()
+	assert.NotNil(t, err)
+
+	records := logRecords(t, output)
+	if assert.Len(t, records, 1) {
+		assert.Equal(t, float64(2), records[0]["retries"])
+		assert.Equal(t, float64(http.StatusServiceUnavailable), records[0]["status"])
+		assert.Equal(t, err.Error(), records[0]["error"])
+	}
+}
+
+func TestWithLoggerAboveDebugLevel(t *testing.T) {
+	logger, output := newTestLogger(slog.LevelInfo)
+	client := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: listVaults}), WithLogger(logger), WithHTTPDump())
+
+	_, err := client.GetVaults()
+
+	assert.Nil(t, err)
+	assert.Empty(t, output.String())
+}
+
+func TestWithHTTPDump(t *testing.T) {
+	logger, output := newTestLogger(slog.LevelDebug)
+	client := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: updateItem}), WithLogger(logger), WithHTTPDump())
+
+	_, err := client.UpdateItem(generateItem(testVaultUUID), testVaultUUID)
+	assert.Nil(t, err)
+
+	assert.NotContains(t, output.String(), validToken)
+	records := logRecords(t, output)
+	if !assert.Len(t, records, 3) {
+		return
+	}
+	request := records[0]["request"].(string)
+	assert.True(t, strings.HasPrefix(request, "PUT /v1/vaults/"+testVaultUUID+"/items/"+testItemUUID+"\n"))
+	assert.Contains(t, request, "Authorization: [REDACTED]\n")
+	assert.Contains(t, request, `"value":"[REDACTED]"`)
+	assert.NotContains(t, request, `"value":"wendy"`)
+	assert.Contains(t, request, `"label":"username"`)
+
+	response := records[1]["response"].(string)
+	assert.NotContains(t, response, `"value":"appleseed"`)
+	assert.Contains(t, response, `"title":"test-item"`)
+}
+
+func TestWithHTTPDumpRedactsOTP(t *testing.T) {
+	logger, output := newTestLogger(slog.LevelDebug)
+	client := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: respondJSON(referencedItem())}), WithLogger(logger), WithHTTPDump())
+
+	item, err := client.GetItemByUUID(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+	assert.Equal(t, "123456", item.Fields[3].TOTP)
+
+	assert.NotContains(t, output.String(), "123456")
+	assert.NotContains(t, output.String(), "otpauth://")
+	assert.Contains(t, output.String(), `\"label\":\"one-time password\"`)
+}
+
+func TestWithHTTPDumpFileContent(t *testing.T) {
+	logger, output := newTestLogger(slog.LevelDebug)
+	client := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: getFileContent}), WithLogger(logger), WithHTTPDump())
+
+	content, err := client.GetFileContent(generateFile())
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("test"), content)
+	assert.Contains(t, output.String(), "[4 bytes of non-JSON content]")
+}
+
+func TestRedactBody(t *testing.T) {
+	body := `[{"op":"replace","path":"/fields/password/value","value":"secret"},{"op":"add","path":"/fields","value":{"label":"pin","value":1234}}]`
+
+	assert.Equal(t,
+		`[{"op":"replace","path":"/fields/password/value","value":"[REDACTED]"},{"op":"add","path":"/fields","value":"[REDACTED]"}]`,
+		redactBody([]byte(body)),
+	)
+	assert.Equal(t, "[6 bytes of non-JSON content]", redactBody([]byte("secret")))
+	assert.Equal(t,
+		`{"content":"[REDACTED]","id":"file","secret":"[REDACTED]"}`,
+		redactBody([]byte(`{"id":"file","content":"dGVzdA==","secret":{"nested":"value"}}`)),
+	)
+}
@@ -0,0 +1,45 @@
+package connect
+
+import (
+	"net/http"
+	"time"
+)
+
+// MetricsRecorder receives metrics about the requests a client sends to Connect and the lookups of a
+// CachingClient. It is set with WithMetrics and WithCacheMetrics. Implementations must be safe for concurrent use.
+// PrometheusMetrics is an implementation that exposes the metrics in the Prometheus text format.
+type MetricsRecorder interface {
+	// ObserveRequest is called once a request to Connect completed, after all its retries. The operation is the
+	// name returned by OperationName. The statusCode is 0 and err is set if no response was received.
+	ObserveRequest(operation string, statusCode int, duration time.Duration, err error)
+	// ObserveCacheLookup is called for every lookup of a vault or item in the cache of a CachingClient.
+	// The kind is either "vault" or "item".
+	ObserveCacheLookup(kind string, result CacheLookupResult)
+}
+
+// CacheLookupResult is the outcome of a lookup in the cache of a CachingClient.
+type CacheLookupResult string
+
+const (
+	// CacheHit is the result of a lookup served from the cache.
+	CacheHit CacheLookupResult = "hit"
+	// CacheStale is the result of a lookup served from the cache after the entry expired, while it is refreshed
+	// or because Connect is unavailable.
+	CacheStale CacheLookupResult = "stale"
+	// CacheMiss is the result of a lookup that was fetched from Connect.
+	CacheMiss CacheLookupResult = "miss"
+)
+
+// Kinds of cache lookups passed to MetricsRecorder.ObserveCacheLookup.
+const (
+	cacheKindVault = "vault"
+	cacheKindItem  = "item"
+)
+
+// recordRequest passes the outcome of a request to the client's MetricsRecorder, if any.
+func (rs *restClient) recordRequest(request *http.Request, statusCode int, duration time.Duration, err error) {
+	if rs.metrics == nil {
+		return
+	}
+	rs.metrics.ObserveRequest(OperationName(request.Context()), statusCode, duration, err)
+}
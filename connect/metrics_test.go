@@ -0,0 +1,108 @@
+package connect
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type observedRequest struct {
+	operation  string
+	statusCode int
+	err        error
+}
+
+type observedLookup struct {
+	kind   string
+	result CacheLookupResult
+}
+
+// recordingMetrics is a MetricsRecorder that keeps everything it observes.
+type recordingMetrics struct {
+	mu       sync.Mutex
+	requests []observedRequest
+	lookups  []observedLookup
+}
+
+func (m *recordingMetrics) ObserveRequest(operation string, statusCode int, duration time.Duration, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests = append(m.requests, observedRequest{operation: operation, statusCode: statusCode, err: err})
+}
+
+func (m *recordingMetrics) ObserveCacheLookup(kind string, result CacheLookupResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups = append(m.lookups, observedLookup{kind: kind, result: result})
+}
+
+func TestWithMetrics(t *testing.T) {
+	metrics := &recordingMetrics{}
+	client := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: listVaults}), WithMetrics(metrics))
+
+	_, err := client.GetVaults()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []observedRequest{{operation: "GetVaults", statusCode: http.StatusOK}}, metrics.requests)
+}
+
+func TestWithMetricsErrors(t *testing.T) {
+	metrics := &recordingMetrics{}
+	connectionErr := errors.New("connection refused")
+	responses := []func(req *http.Request) (*http.Response, error){
+		respondError(apiError(http.StatusNotFound, "Vault not found")),
+		func(req *http.Request) (*http.Response, error) { return nil, connectionErr },
+	}
+	client := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: func(req *http.Request) (*http.Response, error) {
+		respond := responses[0]
+		responses = responses[1:]
+		return respond(req)
+	}}), WithMetrics(metrics))
+
+	client.GetVaultByUUID(testVaultUUID)
+	client.GetVaults()
+
+	assert.Equal(t, []observedRequest{
+		{operation: "GetVaultByUUID", statusCode: http.StatusNotFound},
+		{operation: "GetVaults", err: connectionErr},
+	}, metrics.requests)
+}
+
+func TestWithMetricsRetriesExhausted(t *testing.T) {
+	metrics := &recordingMetrics{}
+	policy := RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	client := NewClient(validHost, validToken,
+		WithHTTPClient(&mockClient{Dofunc: respondError(apiError(http.StatusServiceUnavailable, "unavailable"))}),
+		WithRetryPolicy(policy), WithMetrics(metrics))
+
+	_, err := client.GetVaults()
+
+	var retryErr *RetryError
+	if assert.ErrorAs(t, err, &retryErr) {
+		assert.Equal(t, http.StatusServiceUnavailable, retryErr.StatusCode)
+	}
+	if assert.Len(t, metrics.requests, 1) {
+		assert.Equal(t, http.StatusServiceUnavailable, metrics.requests[0].statusCode)
+	}
+}
+
+func TestWithCacheMetrics(t *testing.T) {
+	metrics := &recordingMetrics{}
+	mock := newCountingMock(serveVaultsAndItems)
+	client := newTestCachingClient(t, mock, WithCacheMetrics(metrics))
+	defer client.Close()
+
+	client.GetItem(testItemUUID, testVaultUUID)
+	client.GetItem(testItemUUID, testVaultUUID)
+	client.GetVault(testVaultUUID)
+
+	assert.Equal(t, []observedLookup{
+		{kind: "item", result: CacheMiss},
+		{kind: "item", result: CacheHit},
+		{kind: "vault", result: CacheMiss},
+	}, metrics.lookups)
+}
@@ -0,0 +1,59 @@
+package connect
+
+import (
+	"context"
+	"net/http"
+)
+
+// Middleware wraps the http.RoundTripper that sends requests to Connect, e.g. to add headers, log requests or
+// inject faults. The name of the client operation a request belongs to can be retrieved from the request's
+// context with OperationName.
+type Middleware func(http.RoundTripper) http.RoundTripper
+
+// operationNameKey is the context key under which the name of the current client operation is stored.
+type operationNameKey struct{}
+
+// OperationName returns the name of the client operation that made the request with the given context, e.g.
+// "GetItemByUUID", or an empty string if the context does not belong to a request made by the client. It is the
+// same name as the one of the operation's tracing span.
+func OperationName(ctx context.Context) string {
+	name, _ := ctx.Value(operationNameKey{}).(string)
+	return name
+}
+
+// withOperationName returns a copy of ctx that carries the name of the current client operation.
+func withOperationName(ctx context.Context, operationName string) context.Context {
+	return context.WithValue(ctx, operationNameKey{}, operationName)
+}
+
+// clientTransport is an http.RoundTripper that sends requests with an HTTPClient, so that middleware can be
+// applied to any HTTPClient.
+type clientTransport struct {
+	client HTTPClient
+}
+
+func (t clientTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	return t.client.Do(request)
+}
+
+// transportClient is an HTTPClient that sends requests with an http.RoundTripper.
+type transportClient struct {
+	transport http.RoundTripper
+}
+
+func (c transportClient) Do(request *http.Request) (*http.Response, error) {
+	return c.transport.RoundTrip(request)
+}
+
+// applyMiddleware returns an HTTPClient that passes every request through the given middleware before sending it
+// with client. The first middleware is the outermost one, so it sees the request first and the response last.
+func applyMiddleware(client HTTPClient, middleware []Middleware) HTTPClient {
+	if len(middleware) == 0 {
+		return client
+	}
+	var transport http.RoundTripper = clientTransport{client: client}
+	for i := len(middleware) - 1; i >= 0; i-- {
+		transport = middleware[i](transport)
+	}
+	return transportClient{transport: transport}
+}
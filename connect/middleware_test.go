@@ -0,0 +1,109 @@
+package connect
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// roundTripperFunc is an http.RoundTripper implemented by a function.
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// recordOperations returns middleware that records the operation name of every request.
+func recordOperations(operations *[]string) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			*operations = append(*operations, OperationName(request.Context()))
+			return next.RoundTrip(request)
+		})
+	}
+}
+
+func TestWithMiddleware(t *testing.T) {
+	addHeader := func(next http.RoundTripper) http.RoundTripper {
+		return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+			request = request.Clone(request.Context())
+			request.Header.Set("X-Team", "platform")
+			return next.RoundTrip(request)
+		})
+	}
+	var operations []string
+
+	var received http.Header
+	client := NewClient(validHost, validToken,
+		WithHTTPClient(&mockClient{Dofunc: func(req *http.Request) (*http.Response, error) {
+			received = req.Header
+			return getItem(req)
+		}}),
+		WithMiddleware(addHeader, recordOperations(&operations)),
+	)
+
+	_, err := client.GetItemByUUID(testItemUUID, testVaultUUID)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "platform", received.Get("X-Team"))
+	assert.Equal(t, "Bearer "+validToken, received.Get("Authorization"))
+	assert.Equal(t, []string{"GetItemByUUID"}, operations)
+}
+
+func TestWithMiddlewareOrder(t *testing.T) {
+	var order []string
+	named := func(name string) Middleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(request *http.Request) (*http.Response, error) {
+				order = append(order, name)
+				response, err := next.RoundTrip(request)
+				order = append(order, name)
+				return response, err
+			})
+		}
+	}
+	client := NewClient(validHost, validToken,
+		WithHTTPClient(&mockClient{Dofunc: listVaults}),
+		WithMiddleware(named("outer")),
+		WithMiddleware(named("inner")),
+	)
+
+	_, err := client.GetVaults()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"outer", "inner", "inner", "outer"}, order)
+}
+
+func TestWithMiddlewareFileContent(t *testing.T) {
+	var operations []string
+	client := NewClient(validHost, validToken,
+		WithHTTPClient(&mockClient{Dofunc: getFileContent}),
+		WithMiddleware(recordOperations(&operations)),
+	)
+
+	_, err := client.GetFileContent(generateFile())
+
+	assert.Nil(t, err)
+	assert.Contains(t, operations, "GetFileContent")
+}
+
+func TestWithMiddlewareRetries(t *testing.T) {
+	var operations []string
+	dofunc, _ := failFirstRequests(2, respondError(apiError(http.StatusServiceUnavailable, "unavailable")), listVaults)
+	client := NewClient(validHost, validToken,
+		WithHTTPClient(&mockClient{Dofunc: dofunc}),
+		WithRetryPolicy(testRetryPolicy),
+		WithMiddleware(recordOperations(&operations)),
+	)
+
+	_, err := client.GetVaults()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"GetVaults", "GetVaults", "GetVaults"}, operations)
+}
+
+func TestOperationNameOutsideOfClient(t *testing.T) {
+	assert.Equal(t, "", OperationName(context.Background()))
+}
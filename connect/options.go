@@ -0,0 +1,251 @@
+package connect
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+
+	"github.com/1Password/connect-sdk-go/filter"
+)
+
+// ClientOption configures the Client returned by NewClient and NewClientFromEnvironment.
+type ClientOption func(*clientConfig)
+
+type clientConfig struct {
+	userAgent  string
+	headers    http.Header
+	httpClient HTTPClient
+	timeout    time.Duration
+	transport  http.RoundTripper
+
+	retryPolicy RetryPolicy
+
+	tracerProvider  trace.TracerProvider
+	tracingDisabled bool
+
+	middleware []Middleware
+
+	logger   *slog.Logger
+	httpDump bool
+
+	metrics MetricsRecorder
+
+	coalesceRequests bool
+	concurrency      int
+	strictLoadStruct bool
+}
+
+// WithUserAgent sets the User-Agent the client identifies itself with to Connect.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *clientConfig) {
+		c.userAgent = userAgent
+	}
+}
+
+// WithHeaders adds the given headers to every request sent to Connect.
+// The Authorization, Content-Type and User-Agent headers are always set by the client and cannot be overridden.
+func WithHeaders(headers http.Header) ClientOption {
+	return func(c *clientConfig) {
+		if c.headers == nil {
+			c.headers = http.Header{}
+		}
+		for key, values := range headers {
+			for _, value := range values {
+				c.headers.Add(key, value)
+			}
+		}
+	}
+}
+
+// WithHTTPClient sets the HTTP client used to send requests to Connect. Defaults to http.DefaultClient.
+func WithHTTPClient(client HTTPClient) ClientOption {
+	return func(c *clientConfig) {
+		c.httpClient = client
+	}
+}
+
+// WithTimeout sets the time limit for requests made to Connect, as described by http.Client.Timeout.
+// It is applied to a copy of the configured HTTP client and has no effect if that client is not an *http.Client.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *clientConfig) {
+		c.timeout = timeout
+	}
+}
+
+// WithTransport sets the http.RoundTripper used to send requests to Connect.
+// It is applied to a copy of the configured HTTP client and has no effect if that client is not an *http.Client.
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *clientConfig) {
+		c.transport = transport
+	}
+}
+
+// WithRetryPolicy sets the policy used to retry requests that failed with a transient error.
+// By default, requests are not retried.
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(c *clientConfig) {
+		c.retryPolicy = policy
+	}
+}
+
+// WithMiddleware adds middleware that every request sent to Connect passes through, including the requests for file
+// contents and every retry of a request. Middleware added first sees the request first. For TLS settings such as
+// client certificates, use WithTransport instead.
+func WithMiddleware(middleware ...Middleware) ClientOption {
+	return func(c *clientConfig) {
+		c.middleware = append(c.middleware, middleware...)
+	}
+}
+
+// WithLogger sets the logger the client logs every request it sends to Connect with, at debug level. The logs
+// contain the operation, method, path, status, duration and number of retries of each request, but never the
+// token or the contents of items and files. By default, nothing is logged.
+func WithLogger(logger *slog.Logger) ClientOption {
+	return func(c *clientConfig) {
+		c.logger = logger
+	}
+}
+
+// WithHTTPDump logs every HTTP request and response exchanged with Connect, including their headers and bodies,
+// at debug level. The Authorization header and every property of a body that is not known to hold metadata, such as
+// the values and one-time passwords of fields, are redacted, and bodies that are not JSON,
+// such as file contents, are left out. Requests are dumped as they are sent, so every retry is dumped and changes
+// made by middleware are included. Uses the logger set with WithLogger, or slog.Default() if none is set.
+func WithHTTPDump() ClientOption {
+	return func(c *clientConfig) {
+		c.httpDump = true
+	}
+}
+
+// WithMetrics sets the MetricsRecorder that receives the count, duration and status of the requests sent to Connect.
+func WithMetrics(recorder MetricsRecorder) ClientOption {
+	return func(c *clientConfig) {
+		c.metrics = recorder
+	}
+}
+
+// WithRequestCoalescing makes concurrent callers that read the same vault, item or file from Connect share a single
+// request. A GET request is only sent if no identical request is in flight, and otherwise waits for the response of
+// that request. Every caller receives its own copy of the response, so items returned to concurrent callers never
+// share memory. Responses are buffered in memory, including the contents of files being downloaded.
+// Callers that share a request are each traced, logged and reported to the MetricsRecorder as if they had sent it,
+// with the time they waited for the response as duration.
+func WithRequestCoalescing() ClientOption {
+	return func(c *clientConfig) {
+		c.coalesceRequests = true
+	}
+}
+
+// WithConcurrency sets the maximum number of items fetched from Connect at the same time by operations that return
+// several full items, such as GetItemsByTitle, GetItemsByID and GetItemsFull. A limit of 1 fetches the items one
+// by one. Defaults to 4.
+func WithConcurrency(limit int) ClientOption {
+	return func(c *clientConfig) {
+		c.concurrency = limit
+	}
+}
+
+// WithStrictLoadStruct makes the LoadStruct methods fail if a struct field is tagged with a label that the item has
+// no field or URL for, unless the field has a default value. By default, such struct fields are left unchanged
+// unless they are tagged as required.
+func WithStrictLoadStruct() ClientOption {
+	return func(c *clientConfig) {
+		c.strictLoadStruct = true
+	}
+}
+
+// WithTracerProvider sets the OpenTelemetry TracerProvider used to create a span for every operation of the client.
+// Defaults to the global TracerProvider registered with otel.SetTracerProvider, which creates no spans unless the
+// application registered one.
+func WithTracerProvider(provider trace.TracerProvider) ClientOption {
+	return func(c *clientConfig) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithTracingDisabled disables creating spans and propagating the trace context to Connect.
+func WithTracingDisabled() ClientOption {
+	return func(c *clientConfig) {
+		c.tracingDisabled = true
+	}
+}
+
+func (c *clientConfig) buildTracer() (trace.Tracer, propagation.TextMapPropagator) {
+	if c.tracingDisabled {
+		return noop.NewTracerProvider().Tracer(tracerName), propagation.NewCompositeTextMapPropagator()
+	}
+	provider := c.tracerProvider
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return provider.Tracer(tracerName, trace.WithInstrumentationVersion(SDKVersion)), propagation.TraceContext{}
+}
+
+func (c *clientConfig) buildHTTPClient() HTTPClient {
+	client := c.configuredHTTPClient()
+	if c.httpDump {
+		logger := c.logger
+		if logger == nil {
+			logger = slog.Default()
+		}
+		client = dumpClient{client: client, logger: logger}
+	}
+	return applyMiddleware(client, c.middleware)
+}
+
+func (c *clientConfig) configuredHTTPClient() HTTPClient {
+	client := c.httpClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if c.timeout == 0 && c.transport == nil {
+		return client
+	}
+
+	stdClient, ok := client.(*http.Client)
+	if !ok {
+		return client
+	}
+	configured := *stdClient
+	if c.timeout != 0 {
+		configured.Timeout = c.timeout
+	}
+	if c.transport != nil {
+		configured.Transport = c.transport
+	}
+	return &configured
+}
+
+func (c *clientConfig) buildRequestGroup() *requestGroup {
+	if !c.coalesceRequests {
+		return nil
+	}
+	return newRequestGroup()
+}
+
+// ListOption configures a request of GetVaultsWithContext, GetItemsWithContext or GetItemSummariesWithContext.
+type ListOption func(*listConfig)
+
+type listConfig struct {
+	filter filter.Filter
+}
+
+// WithFilter makes Connect return only the vaults or items that match the filter.
+func WithFilter(f filter.Filter) ListOption {
+	return func(c *listConfig) {
+		c.filter = f
+	}
+}
+
+func newListConfig(opts []ListOption) listConfig {
+	var c listConfig
+	for _, opt := range opts {
+		opt(&c)
+	}
+	return c
+}
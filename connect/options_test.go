@@ -0,0 +1,73 @@
+package connect
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewClientWithOptions(t *testing.T) {
+	httpClient := &http.Client{}
+	transport := &http.Transport{}
+
+	client := NewClient(validHost, validToken,
+		WithUserAgent("testSuite"),
+		WithHTTPClient(httpClient),
+		WithTimeout(5*time.Second),
+		WithTransport(transport),
+	)
+
+	restClient, ok := client.(*restClient)
+	if !ok {
+		t.Log("Unable to cast client to rest client. Was expecting restClient")
+		t.FailNow()
+	}
+
+	assert.Equal(t, "testSuite", restClient.userAgent)
+
+	configured, ok := restClient.client.(*http.Client)
+	if !ok {
+		t.Log("Expected the configured client to be an *http.Client")
+		t.FailNow()
+	}
+	assert.Equal(t, 5*time.Second, configured.Timeout)
+	assert.Equal(t, transport, configured.Transport)
+	assert.Equal(t, time.Duration(0), httpClient.Timeout, "the provided client should not be modified")
+}
+
+func TestNewClientDefaultHTTPClient(t *testing.T) {
+	restClient := NewClient(validHost, validToken).(*restClient)
+
+	assert.Equal(t, http.DefaultClient, restClient.client)
+}
+
+func TestNewClientWithCustomHTTPClient(t *testing.T) {
+	restClient := NewClient(validHost, validToken, WithHTTPClient(mockHTTPClient), WithTimeout(time.Second)).(*restClient)
+
+	assert.Equal(t, mockHTTPClient, restClient.client)
+}
+
+func TestNewClientWithHeaders(t *testing.T) {
+	var received http.Header
+	mock := &mockClient{
+		Dofunc: func(req *http.Request) (*http.Response, error) {
+			received = req.Header
+			return listVaults(req)
+		},
+	}
+
+	client := NewClient(validHost, validToken,
+		WithHTTPClient(mock),
+		WithHeaders(http.Header{
+			"X-Team":        []string{"platform"},
+			"Authorization": []string{"Bearer overridden"},
+		}),
+	)
+
+	_, err := client.GetVaults()
+	assert.Nil(t, err)
+	assert.Equal(t, "platform", received.Get("X-Team"))
+	assert.Equal(t, "Bearer "+validToken, received.Get("Authorization"))
+}
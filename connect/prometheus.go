@@ -0,0 +1,203 @@
+package connect
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultLatencyBuckets are the upper bounds in seconds of the buckets of the request duration histogram.
+// They match the default buckets of the Prometheus client libraries.
+var defaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// PrometheusMetrics is a MetricsRecorder that exposes the recorded metrics in the Prometheus text exposition
+// format, without depending on a Prometheus client library. It serves the metrics as an http.Handler, e.g. on
+// /metrics, and can write them anywhere with WriteTo. The exposed metrics are:
+//
+//   - connect_client_requests_total: requests sent to Connect, by operation and status code
+//   - connect_client_request_errors_total: requests that failed or got a status of 400 or higher, by operation and
+//     status code
+//   - connect_client_request_duration_seconds: histogram of the duration of requests, by operation
+//   - connect_client_cache_lookups_total: lookups in the cache of a CachingClient, by kind and result
+//
+// The status code label is "none" for requests that got no response.
+type PrometheusMetrics struct {
+	mu        sync.Mutex
+	buckets   []float64
+	requests  map[requestLabels]uint64
+	errors    map[requestLabels]uint64
+	latencies map[string]*histogram
+	lookups   map[cacheLabels]uint64
+}
+
+type requestLabels struct {
+	operation string
+	status    string
+}
+
+type cacheLabels struct {
+	kind   string
+	result CacheLookupResult
+}
+
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewPrometheusMetrics returns an empty PrometheusMetrics.
+func NewPrometheusMetrics() *PrometheusMetrics {
+	return &PrometheusMetrics{
+		buckets:   defaultLatencyBuckets,
+		requests:  map[requestLabels]uint64{},
+		errors:    map[requestLabels]uint64{},
+		latencies: map[string]*histogram{},
+		lookups:   map[cacheLabels]uint64{},
+	}
+}
+
+func (m *PrometheusMetrics) ObserveRequest(operation string, statusCode int, duration time.Duration, err error) {
+	labels := requestLabels{operation: operation, status: "none"}
+	if statusCode != 0 {
+		labels.status = strconv.Itoa(statusCode)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.requests[labels]++
+	if err != nil || statusCode >= http.StatusBadRequest {
+		m.errors[labels]++
+	}
+
+	h, ok := m.latencies[operation]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(m.buckets))}
+		m.latencies[operation] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+func (m *PrometheusMetrics) ObserveCacheLookup(kind string, result CacheLookupResult) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lookups[cacheLabels{kind: kind, result: result}]++
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format to w.
+func (m *PrometheusMetrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := &countingWriter{w: bufio.NewWriter(w)}
+
+	writeHeader(out, "connect_client_requests_total", "counter", "Number of requests sent to Connect.")
+	writeRequestCounters(out, "connect_client_requests_total", m.requests)
+
+	writeHeader(out, "connect_client_request_errors_total", "counter", "Number of requests to Connect that failed or got an error status.")
+	writeRequestCounters(out, "connect_client_request_errors_total", m.errors)
+
+	writeHeader(out, "connect_client_request_duration_seconds", "histogram", "Duration of requests to Connect, including retries.")
+	operations := make([]string, 0, len(m.latencies))
+	for operation := range m.latencies {
+		operations = append(operations, operation)
+	}
+	sort.Strings(operations)
+	for _, operation := range operations {
+		h := m.latencies[operation]
+		label := `operation="` + escapeLabelValue(operation) + `"`
+		for i, bound := range m.buckets {
+			fmt.Fprintf(out, "connect_client_request_duration_seconds_bucket{%s,le=%q} %d\n", label, formatFloat(bound), h.counts[i])
+		}
+		fmt.Fprintf(out, "connect_client_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", label, h.count)
+		fmt.Fprintf(out, "connect_client_request_duration_seconds_sum{%s} %s\n", label, formatFloat(h.sum))
+		fmt.Fprintf(out, "connect_client_request_duration_seconds_count{%s} %d\n", label, h.count)
+	}
+
+	writeHeader(out, "connect_client_cache_lookups_total", "counter", "Number of lookups in the cache of a CachingClient.")
+	lookups := make([]cacheLabels, 0, len(m.lookups))
+	for labels := range m.lookups {
+		lookups = append(lookups, labels)
+	}
+	sort.Slice(lookups, func(i, j int) bool {
+		if lookups[i].kind != lookups[j].kind {
+			return lookups[i].kind < lookups[j].kind
+		}
+		return lookups[i].result < lookups[j].result
+	})
+	for _, labels := range lookups {
+		fmt.Fprintf(out, "connect_client_cache_lookups_total{kind=\"%s\",result=\"%s\"} %d\n",
+			escapeLabelValue(labels.kind), escapeLabelValue(string(labels.result)), m.lookups[labels])
+	}
+
+	if out.err != nil {
+		return out.n, out.err
+	}
+	return out.n, out.w.(*bufio.Writer).Flush()
+}
+
+func writeHeader(w io.Writer, name string, metricType string, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeRequestCounters(w io.Writer, name string, counters map[requestLabels]uint64) {
+	labels := make([]requestLabels, 0, len(counters))
+	for l := range counters {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].operation != labels[j].operation {
+			return labels[i].operation < labels[j].operation
+		}
+		return labels[i].status < labels[j].status
+	})
+	for _, l := range labels {
+		fmt.Fprintf(w, "%s{operation=\"%s\",status=\"%s\"} %d\n", name, escapeLabelValue(l.operation), escapeLabelValue(l.status), counters[l])
+	}
+}
+
+// escapeLabelValue escapes a label value as required by the Prometheus text exposition format.
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// countingWriter counts the bytes written to w and remembers the first error.
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	c.err = err
+	return n, err
+}
@@ -0,0 +1,89 @@
+package connect
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusMetrics(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	metrics.ObserveRequest("GetItem", http.StatusOK, 20*time.Millisecond, nil)
+	metrics.ObserveRequest("GetItem", http.StatusOK, 2*time.Second, nil)
+	metrics.ObserveRequest("GetItem", http.StatusNotFound, 3*time.Millisecond, nil)
+	metrics.ObserveRequest("GetVaults", 0, 20*time.Second, errors.New("connection refused"))
+	metrics.ObserveCacheLookup("item", CacheHit)
+	metrics.ObserveCacheLookup("item", CacheHit)
+	metrics.ObserveCacheLookup("item", CacheMiss)
+
+	var out strings.Builder
+	n, err := metrics.WriteTo(&out)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(out.Len()), n)
+	assert.Equal(t, `# HELP connect_client_requests_total Number of requests sent to Connect.
+# TYPE connect_client_requests_total counter
+connect_client_requests_total{operation="GetItem",status="200"} 2
+connect_client_requests_total{operation="GetItem",status="404"} 1
+connect_client_requests_total{operation="GetVaults",status="none"} 1
+# HELP connect_client_request_errors_total Number of requests to Connect that failed or got an error status.
+# TYPE connect_client_request_errors_total counter
+connect_client_request_errors_total{operation="GetItem",status="404"} 1
+connect_client_request_errors_total{operation="GetVaults",status="none"} 1
+# HELP connect_client_request_duration_seconds Duration of requests to Connect, including retries.
+# TYPE connect_client_request_duration_seconds histogram
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="0.005"} 1
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="0.01"} 1
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="0.025"} 2
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="0.05"} 2
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="0.1"} 2
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="0.25"} 2
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="0.5"} 2
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="1"} 2
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="2.5"} 3
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="5"} 3
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="10"} 3
+connect_client_request_duration_seconds_bucket{operation="GetItem",le="+Inf"} 3
+connect_client_request_duration_seconds_sum{operation="GetItem"} 2.023
+connect_client_request_duration_seconds_count{operation="GetItem"} 3
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="0.005"} 0
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="0.01"} 0
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="0.025"} 0
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="0.05"} 0
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="0.1"} 0
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="0.25"} 0
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="0.5"} 0
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="1"} 0
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="2.5"} 0
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="5"} 0
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="10"} 0
+connect_client_request_duration_seconds_bucket{operation="GetVaults",le="+Inf"} 1
+connect_client_request_duration_seconds_sum{operation="GetVaults"} 20
+connect_client_request_duration_seconds_count{operation="GetVaults"} 1
+# HELP connect_client_cache_lookups_total Number of lookups in the cache of a CachingClient.
+# TYPE connect_client_cache_lookups_total counter
+connect_client_cache_lookups_total{kind="item",result="hit"} 2
+connect_client_cache_lookups_total{kind="item",result="miss"} 1
+`, out.String())
+}
+
+func TestPrometheusMetricsHandler(t *testing.T) {
+	metrics := NewPrometheusMetrics()
+	client := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: listVaults}), WithMetrics(metrics))
+	client.GetVaults()
+
+	recorder := httptest.NewRecorder()
+	metrics.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), `connect_client_requests_total{operation="GetVaults",status="200"} 1`)
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	assert.Equal(t, `a\\b\"c\nd`, escapeLabelValue("a\\b\"c\nd"))
+}
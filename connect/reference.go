@@ -0,0 +1,248 @@
+package connect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+const referenceScheme = "op://"
+
+// Attributes of a field or file that a secret reference can select with the attribute query parameter.
+const (
+	// ReferenceAttributeValue selects the value of a field, or the content of a file. It is the default.
+	ReferenceAttributeValue = "value"
+	// ReferenceAttributeOTP selects the current one-time password of an OTP field.
+	ReferenceAttributeOTP = "otp"
+	// ReferenceAttributeID selects the ID of a field or file.
+	ReferenceAttributeID = "id"
+	// ReferenceAttributeLabel selects the label of a field, or the name of a file.
+	ReferenceAttributeLabel = "label"
+	// ReferenceAttributeType selects the type of a field, such as CONCEALED, or FILE for a file.
+	ReferenceAttributeType = "type"
+	// ReferenceAttributePurpose selects the purpose of a field, such as PASSWORD.
+	ReferenceAttributePurpose = "purpose"
+)
+
+// ErrInvalidReference is matched by errors.Is if a secret reference could not be parsed. The reason can be
+// retrieved by using errors.As with a *ReferenceError.
+var ErrInvalidReference = errors.New("invalid secret reference")
+
+// ReferenceError is returned if a secret reference does not follow the op://vault/item/[section/]field syntax.
+type ReferenceError struct {
+	Reference string
+	Reason    string
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("invalid secret reference %q: %s", e.Reference, e.Reason)
+}
+
+func (e *ReferenceError) Is(target error) bool {
+	return target == ErrInvalidReference
+}
+
+// SecretReference identifies a field or file of an item with a URI of the form
+// op://vault/item/[section/]field[?attribute=<attribute>]. The vault, item, section and field can each be given by
+// their title or label, or by their ID.
+type SecretReference struct {
+	Vault   string
+	Item    string
+	Section string
+	// Field is the label or ID of a field, or the name or ID of a file.
+	Field string
+	// Attribute is the attribute of the field or file to resolve, one of the ReferenceAttribute constants.
+	// Defaults to ReferenceAttributeValue.
+	Attribute string
+}
+
+// ParseSecretReference parses a secret reference such as op://prod/postgres/credentials/password.
+func ParseSecretReference(ref string) (*SecretReference, error) {
+	invalid := func(reason string) error {
+		return &ReferenceError{Reference: ref, Reason: reason}
+	}
+
+	rest, ok := strings.CutPrefix(ref, referenceScheme)
+	if !ok {
+		return nil, invalid(fmt.Sprintf("it must start with %q", referenceScheme))
+	}
+
+	path, rawQuery, _ := strings.Cut(rest, "?")
+	segments := strings.Split(path, "/")
+	if len(segments) < 3 || len(segments) > 4 {
+		return nil, invalid("it must have the form op://vault/item/[section/]field")
+	}
+	for _, segment := range segments {
+		if segment == "" {
+			return nil, invalid("the vault, item, section and field cannot be empty")
+		}
+	}
+
+	parsed := &SecretReference{
+		Vault:     segments[0],
+		Item:      segments[1],
+		Field:     segments[len(segments)-1],
+		Attribute: ReferenceAttributeValue,
+	}
+	if len(segments) == 4 {
+		parsed.Section = segments[2]
+	}
+
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return nil, invalid("the query cannot be parsed")
+	}
+	for key, values := range query {
+		if key != "attribute" {
+			return nil, invalid(fmt.Sprintf("unknown query parameter %q", key))
+		}
+		parsed.Attribute = strings.ToLower(values[len(values)-1])
+	}
+	switch parsed.Attribute {
+	case ReferenceAttributeValue, ReferenceAttributeID, ReferenceAttributeLabel, ReferenceAttributeType, ReferenceAttributePurpose:
+	case ReferenceAttributeOTP, "totp":
+		parsed.Attribute = ReferenceAttributeOTP
+	default:
+		return nil, invalid(fmt.Sprintf("unknown attribute %q", parsed.Attribute))
+	}
+
+	return parsed, nil
+}
+
+// String returns the reference as a URI.
+func (r SecretReference) String() string {
+	path := []string{r.Vault, r.Item, r.Field}
+	if r.Section != "" {
+		path = []string{r.Vault, r.Item, r.Section, r.Field}
+	}
+	ref := referenceScheme + strings.Join(path, "/")
+	if r.Attribute != "" && r.Attribute != ReferenceAttributeValue {
+		ref += "?attribute=" + r.Attribute
+	}
+	return ref
+}
+
+// resolveReference returns the value the secret reference points to, using client to fetch the item.
+func resolveReference(ctx context.Context, client Client, ref string) (string, error) {
+	parsed, err := ParseSecretReference(ref)
+	if err != nil {
+		return "", err
+	}
+
+	item, err := client.GetItemWithContext(ctx, parsed.Item, parsed.Vault)
+	if err != nil {
+		return "", err
+	}
+
+	return resolveReferenceInItem(ctx, client, parsed, item)
+}
+
+// resolveReferenceInItem returns the value the secret reference points to in the given item, which it must refer to.
+func resolveReferenceInItem(ctx context.Context, client Client, ref *SecretReference, item *onepassword.Item) (string, error) {
+	field, err := referencedField(ref, item)
+	if err != nil {
+		return "", err
+	}
+	if field != nil {
+		return fieldAttribute(ref, field)
+	}
+
+	file := referencedFile(ref, item)
+	if file == nil {
+		return "", &classifiedError{
+			class: ErrFieldNotFound,
+			err:   fmt.Errorf("item %q has no field or file matching %q", item.Title, ref),
+		}
+	}
+
+	switch ref.Attribute {
+	case ReferenceAttributeValue:
+		content, err := client.GetFileContentWithContext(ctx, file)
+		if err != nil {
+			return "", err
+		}
+		return string(content), nil
+	case ReferenceAttributeID:
+		return file.ID, nil
+	case ReferenceAttributeLabel:
+		return file.Name, nil
+	case ReferenceAttributeType:
+		return string(onepassword.FieldTypeFile), nil
+	}
+	return "", fmt.Errorf("attribute %q is not available for file %q", ref.Attribute, file.Name)
+}
+
+// referencedField returns the field of the item the reference points to, or nil if there is none. Labels are
+// matched case-insensitively, like those of sections. An error is returned if more than one field matches.
+func referencedField(ref *SecretReference, item *onepassword.Item) (*onepassword.ItemField, error) {
+	var match *onepassword.ItemField
+	for _, f := range item.Fields {
+		if !strings.EqualFold(f.Label, ref.Field) && f.ID != ref.Field {
+			continue
+		}
+		if ref.Section != "" && !referencedSection(ref, item, f.Section) {
+			continue
+		}
+		if match != nil {
+			return nil, fmt.Errorf("more than one field of item %q matches %q, use the section or field ID to select one", item.Title, ref)
+		}
+		match = f
+	}
+	return match, nil
+}
+
+// referencedFile returns the file of the item the reference points to, or nil if there is none. Names are matched
+// case-insensitively, like the labels of fields.
+func referencedFile(ref *SecretReference, item *onepassword.Item) *onepassword.File {
+	for _, f := range item.Files {
+		if !strings.EqualFold(f.Name, ref.Field) && f.ID != ref.Field {
+			continue
+		}
+		if ref.Section != "" && !referencedSection(ref, item, f.Section) {
+			continue
+		}
+		return f
+	}
+	return nil
+}
+
+// referencedSection reports whether the section of a field or file is the section the reference points to. Labels
+// are matched case-insensitively, like the opsection tag of LoadStruct.
+func referencedSection(ref *SecretReference, item *onepassword.Item, section *onepassword.ItemSection) bool {
+	if section == nil {
+		return false
+	}
+	if section.ID == ref.Section || strings.EqualFold(section.Label, ref.Section) {
+		return true
+	}
+	// The section of a field usually only has its ID set, so look up its label in the sections of the item
+	for _, s := range item.Sections {
+		if s.ID == section.ID && strings.EqualFold(s.Label, ref.Section) {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldAttribute(ref *SecretReference, field *onepassword.ItemField) (string, error) {
+	switch ref.Attribute {
+	case ReferenceAttributeOTP:
+		if field.TOTP == "" {
+			return "", fmt.Errorf("field %q has no one-time password", field.Label)
+		}
+		return field.TOTP, nil
+	case ReferenceAttributeID:
+		return field.ID, nil
+	case ReferenceAttributeLabel:
+		return field.Label, nil
+	case ReferenceAttributeType:
+		return string(field.Type), nil
+	case ReferenceAttributePurpose:
+		return string(field.Purpose), nil
+	}
+	return field.Value, nil
+}
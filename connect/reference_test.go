@@ -0,0 +1,167 @@
+package connect
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+func TestParseSecretReference(t *testing.T) {
+	cases := map[string]struct {
+		ref      string
+		expected SecretReference
+	}{
+		"field": {
+			ref:      "op://prod/postgres/password",
+			expected: SecretReference{Vault: "prod", Item: "postgres", Field: "password", Attribute: ReferenceAttributeValue},
+		},
+		"field in section": {
+			ref:      "op://prod/postgres/credentials/password",
+			expected: SecretReference{Vault: "prod", Item: "postgres", Section: "credentials", Field: "password", Attribute: ReferenceAttributeValue},
+		},
+		"titles with spaces": {
+			ref:      "op://Shared Vault/Demo Database/password",
+			expected: SecretReference{Vault: "Shared Vault", Item: "Demo Database", Field: "password", Attribute: ReferenceAttributeValue},
+		},
+		"otp attribute": {
+			ref:      "op://prod/github/one-time password?attribute=otp",
+			expected: SecretReference{Vault: "prod", Item: "github", Field: "one-time password", Attribute: ReferenceAttributeOTP},
+		},
+		"totp attribute": {
+			ref:      "op://prod/github/one-time password?attribute=TOTP",
+			expected: SecretReference{Vault: "prod", Item: "github", Field: "one-time password", Attribute: ReferenceAttributeOTP},
+		},
+		"type attribute": {
+			ref:      "op://prod/postgres/password?attribute=type",
+			expected: SecretReference{Vault: "prod", Item: "postgres", Field: "password", Attribute: ReferenceAttributeType},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			ref, err := ParseSecretReference(tc.ref)
+			assert.Nil(t, err)
+			if assert.NotNil(t, ref) {
+				assert.Equal(t, tc.expected, *ref)
+			}
+		})
+	}
+}
+
+func TestParseSecretReferenceErrors(t *testing.T) {
+	cases := map[string]string{
+		"missing scheme":          "prod/postgres/password",
+		"other scheme":            "https://prod/postgres/password",
+		"too few segments":        "op://prod/postgres",
+		"too many segments":       "op://prod/postgres/credentials/password/extra",
+		"empty segment":           "op://prod//password",
+		"trailing slash":          "op://prod/postgres/password/",
+		"unknown attribute":       "op://prod/postgres/password?attribute=colour",
+		"empty attribute":         "op://prod/postgres/password?attribute=",
+		"unknown query parameter": "op://prod/postgres/password?format=json",
+	}
+
+	for name, ref := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseSecretReference(ref)
+			assert.ErrorIs(t, err, ErrInvalidReference)
+			var refErr *ReferenceError
+			if assert.ErrorAs(t, err, &refErr) {
+				assert.Equal(t, ref, refErr.Reference)
+				assert.NotEmpty(t, refErr.Reason)
+			}
+		})
+	}
+}
+
+func TestSecretReferenceString(t *testing.T) {
+	for _, ref := range []string{
+		"op://prod/postgres/password",
+		"op://prod/postgres/credentials/password",
+		"op://prod/github/one-time password?attribute=otp",
+	} {
+		parsed, err := ParseSecretReference(ref)
+		if assert.Nil(t, err) {
+			assert.Equal(t, ref, parsed.String())
+		}
+	}
+}
+
+// referencedItem returns an item with fields in and outside of sections, an OTP field and a file.
+func referencedItem() onepassword.Item {
+	item := itemWithFields(
+		&onepassword.ItemField{ID: "username", Label: "username", Value: "wendy", Purpose: onepassword.FieldPurposeUsername},
+		&onepassword.ItemField{ID: "password", Label: "password", Value: "appleseed", Type: onepassword.FieldTypeConcealed},
+		&onepassword.ItemField{ID: "db-password", Label: "password", Value: "db-secret", Section: &onepassword.ItemSection{ID: "db"}},
+		&onepassword.ItemField{ID: "totp", Label: "one-time password", Value: "otpauth://totp/test", TOTP: "123456", Type: onepassword.FieldTypeOTP},
+	)
+	item.Sections = []*onepassword.ItemSection{{ID: "db", Label: "database"}}
+	item.Files = []*onepassword.File{generateFile()}
+	return item
+}
+
+func serveReferencedItem(req *http.Request) (*http.Response, error) {
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/v1/files/"):
+		return getFileContent(req)
+	case req.URL.Query().Get("filter") != "":
+		return respondJSON([]onepassword.Item{referencedItem()})(req)
+	}
+	return respondJSON(referencedItem())(req)
+}
+
+func TestResolveReference(t *testing.T) {
+	cases := map[string]struct {
+		ref      string
+		expected string
+	}{
+		"field by label":        {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/username", expected: "wendy"},
+		"item by title":         {ref: "op://" + testVaultUUID + "/test-item/username", expected: "wendy"},
+		"field by ID":           {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/db-password", expected: "db-secret"},
+		"section by label":      {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/database/password", expected: "db-secret"},
+		"section label case":    {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/Database/password", expected: "db-secret"},
+		"field label case":      {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/Username", expected: "wendy"},
+		"file name case":        {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/TestFile.txt", expected: "test"},
+		"section by ID":         {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/db/password", expected: "db-secret"},
+		"otp":                   {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/one-time password?attribute=otp", expected: "123456"},
+		"type":                  {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/one-time password?attribute=type", expected: "OTP"},
+		"purpose":               {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/username?attribute=purpose", expected: "USERNAME"},
+		"file content":          {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/testfile.txt", expected: "test"},
+		"file ID":               {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/testfile.txt?attribute=id", expected: testID},
+		"field ID by attribute": {ref: "op://" + testVaultUUID + "/" + testItemUUID + "/database/password?attribute=id", expected: "db-password"},
+	}
+
+	mockHTTPClient.Dofunc = serveReferencedItem
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			value, err := testClient.ResolveReference(tc.ref)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, value)
+		})
+	}
+}
+
+func TestResolveReferenceErrors(t *testing.T) {
+	mockHTTPClient.Dofunc = serveReferencedItem
+
+	_, err := testClient.ResolveReference("op://" + testVaultUUID + "/" + testItemUUID + "/missing")
+	assert.ErrorIs(t, err, ErrFieldNotFound)
+
+	_, err = testClient.ResolveReference("op://" + testVaultUUID + "/" + testItemUUID + "/password")
+	assert.ErrorContains(t, err, "more than one field")
+
+	_, err = testClient.ResolveReference("op://" + testVaultUUID + "/" + testItemUUID + "/username?attribute=otp")
+	assert.ErrorContains(t, err, "no one-time password")
+
+	_, err = testClient.ResolveReference(testVaultUUID + "/" + testItemUUID + "/username")
+	assert.ErrorIs(t, err, ErrInvalidReference)
+
+	errResult := apiError(http.StatusNotFound, "Item not found")
+	mockHTTPClient.Dofunc = respondError(errResult)
+	_, err = testClient.ResolveReference("op://" + testVaultUUID + "/" + testItemUUID + "/username")
+	assert.ErrorIs(t, err, errResult)
+}
@@ -0,0 +1,166 @@
+package connect
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy describes how requests to Connect that failed with a transient error are retried.
+// Requests are retried if sending them failed, or if Connect responded with one of the statuses
+// 429 Too Many Requests, 502 Bad Gateway, 503 Service Unavailable or 504 Gateway Timeout.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of times a request is sent, including the first attempt.
+	// A value of 1 or lower disables retries.
+	MaxAttempts int
+	// InitialBackoff is the time to wait before the first retry. It doubles with every following retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the time to wait between two attempts. If Connect asks to wait longer with the Retry-After
+	// header, the request is not retried.
+	MaxBackoff time.Duration
+	// RetryNonIdempotent enables retrying POST and PATCH requests. Retrying these may apply the request twice,
+	// e.g. creating an item twice, if Connect processed the request but the response did not reach the client.
+	RetryNonIdempotent bool
+}
+
+// DefaultRetryPolicy is a reasonable policy to use with WithRetryPolicy. It retries idempotent requests twice.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: 250 * time.Millisecond,
+	MaxBackoff:     5 * time.Second,
+}
+
+// RetryError is returned when a request still failed after it was retried, whether the last attempt failed with a
+// status that is retried or with another error, such as 500 Internal Server Error or 404 Not Found.
+// The error of the last attempt can be retrieved with errors.Is and errors.As.
+type RetryError struct {
+	// Retries is the number of times the request was retried after the first attempt.
+	Retries int
+	// StatusCode is the status of the response to the last attempt, or 0 if it received no response.
+	StatusCode int
+	Err        error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("request failed after %d retries: %s", e.Retries, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry returns true if the request can be retried after it resulted in the given response or error.
+func (p RetryPolicy) shouldRetry(request *http.Request, response *http.Response, err error) bool {
+	if !p.RetryNonIdempotent && !isIdempotent(request.Method) {
+		return false
+	}
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		// The body has been consumed and cannot be sent again
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return isRetryableStatus(response.StatusCode)
+}
+
+// backoff returns the time to wait before the next attempt. The Retry-After header of the response is honored if
+// present, otherwise the wait time grows exponentially with jitter. It returns false if the Retry-After header asks
+// for a longer wait than MaxBackoff, in which case the request should not be retried.
+func (p RetryPolicy) backoff(attempt int, response *http.Response) (time.Duration, bool) {
+	if response != nil {
+		if wait, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+			if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+				return 0, false
+			}
+			return wait, true
+		}
+	}
+
+	wait := p.InitialBackoff
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || wait < p.MaxBackoff); i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	if wait <= 0 {
+		return 0, true
+	}
+	// Wait between half and the full backoff so that concurrent clients do not retry in lockstep
+	half := wait / 2
+	return half + time.Duration(rand.Int63n(int64(wait-half)+1)), true
+}
+
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func isRetryableStatus(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// rewindRequest returns a copy of the request that can be sent again.
+func rewindRequest(request *http.Request) (*http.Request, error) {
+	rewound := request.Clone(request.Context())
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		rewound.Body = body
+	}
+	return rewound, nil
+}
+
+// sleep waits for the given duration or until the context is done, whichever happens first.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,248 @@
+package connect
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/1Password/connect-sdk-go/onepassword"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxAttempts:    3,
+	InitialBackoff: time.Millisecond,
+	MaxBackoff:     time.Millisecond,
+}
+
+func newRetryTestClient(policy RetryPolicy, dofunc func(req *http.Request) (*http.Response, error)) Client {
+	return NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: dofunc}), WithRetryPolicy(policy))
+}
+
+func failFirstRequests(failures int, failure func(req *http.Request) (*http.Response, error), success func(req *http.Request) (*http.Response, error)) (func(req *http.Request) (*http.Response, error), *int) {
+	count := 0
+	return func(req *http.Request) (*http.Response, error) {
+		count++
+		if count <= failures {
+			return failure(req)
+		}
+		return success(req)
+	}, &count
+}
+
+func TestRetryTransientStatus(t *testing.T) {
+	dofunc, count := failFirstRequests(2, respondError(apiError(http.StatusServiceUnavailable, "unavailable")), getItem)
+	client := newRetryTestClient(testRetryPolicy, dofunc)
+
+	item, err := client.GetItem(testID, testID)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, item)
+	assert.Equal(t, 3, *count)
+}
+
+func TestRetryConnectionError(t *testing.T) {
+	failure := func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("connection reset by peer")
+	}
+	dofunc, count := failFirstRequests(1, failure, listVaults)
+	client := newRetryTestClient(testRetryPolicy, dofunc)
+
+	vaults, err := client.GetVaults()
+
+	assert.Nil(t, err)
+	assert.Len(t, vaults, 1)
+	assert.Equal(t, 2, *count)
+}
+
+func TestRetryExhausted(t *testing.T) {
+	errResult := apiError(http.StatusBadGateway, "bad gateway")
+	dofunc, count := failFirstRequests(5, respondError(errResult), getItem)
+	client := newRetryTestClient(testRetryPolicy, dofunc)
+
+	_, err := client.GetItemByUUID(testID, testID)
+
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Logf("Expected a RetryError, got %v", err)
+		t.FailNow()
+	}
+	assert.Equal(t, 2, retryErr.Retries)
+	assert.ErrorIs(t, err, errResult)
+	assert.Equal(t, 3, *count)
+}
+
+func TestRetryNonRetryableStatus(t *testing.T) {
+	errResult := apiError(http.StatusNotFound, "item not found")
+	dofunc, count := failFirstRequests(5, respondError(errResult), getItem)
+	client := newRetryTestClient(testRetryPolicy, dofunc)
+
+	_, err := client.GetItemByUUID(testID, testID)
+
+	assert.ErrorIs(t, err, errResult)
+	assert.Equal(t, 1, *count)
+}
+
+func TestRetrySkipsNonIdempotentRequests(t *testing.T) {
+	errResult := apiError(http.StatusServiceUnavailable, "unavailable")
+	dofunc, count := failFirstRequests(1, respondError(errResult), createItem)
+	client := newRetryTestClient(testRetryPolicy, dofunc)
+
+	_, err := client.CreateItem(generateItem(defaultVault), defaultVault)
+
+	assert.ErrorIs(t, err, errResult)
+	assert.Equal(t, 1, *count)
+}
+
+func TestRetryNonIdempotentRequestsResendsBody(t *testing.T) {
+	policy := testRetryPolicy
+	policy.RetryNonIdempotent = true
+
+	var bodies []string
+	record := func(next func(req *http.Request) (*http.Response, error)) func(req *http.Request) (*http.Response, error) {
+		return func(req *http.Request) (*http.Response, error) {
+			body, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(body))
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			return next(req)
+		}
+	}
+	dofunc, count := failFirstRequests(1, record(respondError(apiError(http.StatusServiceUnavailable, "unavailable"))), record(createItem))
+	client := newRetryTestClient(policy, dofunc)
+
+	item, err := client.CreateItem(generateItem(defaultVault), defaultVault)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, item)
+	assert.Equal(t, 2, *count)
+	assert.Equal(t, bodies[0], bodies[1])
+	assert.NotEmpty(t, bodies[0])
+}
+
+func TestRetryDisabledByDefault(t *testing.T) {
+	dofunc, count := failFirstRequests(1, respondError(apiError(http.StatusServiceUnavailable, "unavailable")), listVaults)
+	client := NewClient(validHost, validToken, WithHTTPClient(&mockClient{Dofunc: dofunc}))
+
+	_, err := client.GetVaults()
+
+	assert.Error(t, err)
+	assert.Equal(t, 1, *count)
+}
+
+func TestRetryFollowedByStatusThatIsNotRetried(t *testing.T) {
+	dofunc, count := failFirstRequests(1, respondError(apiError(http.StatusServiceUnavailable, "unavailable")),
+		respondError(apiError(http.StatusInternalServerError, "internal error")))
+	client := newRetryTestClient(testRetryPolicy, dofunc)
+
+	_, err := client.GetVaults()
+
+	var retryErr *RetryError
+	if assert.ErrorAs(t, err, &retryErr) {
+		assert.Equal(t, 1, retryErr.Retries)
+		assert.Equal(t, http.StatusInternalServerError, retryErr.StatusCode)
+	}
+	var apiErr *onepassword.Error
+	if assert.ErrorAs(t, err, &apiErr) {
+		assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	}
+	assert.Equal(t, 2, *count)
+}
+
+func TestRetryAfterLongerThanMaxBackoff(t *testing.T) {
+	count := 0
+	client := newRetryTestClient(testRetryPolicy, func(req *http.Request) (*http.Response, error) {
+		count++
+		response, err := respondError(apiError(http.StatusTooManyRequests, "slow down"))(req)
+		if count > 1 {
+			response.Header = http.Header{"Retry-After": []string{"3600"}}
+		}
+		return response, err
+	})
+
+	start := time.Now()
+	_, err := client.GetVaults()
+
+	assert.Less(t, time.Since(start), time.Second)
+	var retryErr *RetryError
+	if assert.ErrorAs(t, err, &retryErr) {
+		assert.Equal(t, 1, retryErr.Retries)
+	}
+	assert.Equal(t, 2, count)
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	cases := map[string]struct {
+		attempt    int
+		retryAfter string
+		min        time.Duration
+		max        time.Duration
+	}{
+		"first retry": {
+			attempt: 1,
+			min:     50 * time.Millisecond,
+			max:     100 * time.Millisecond,
+		},
+		"third retry": {
+			attempt: 3,
+			min:     200 * time.Millisecond,
+			max:     400 * time.Millisecond,
+		},
+		"capped": {
+			attempt: 10,
+			min:     500 * time.Millisecond,
+			max:     time.Second,
+		},
+		"retry after seconds": {
+			attempt:    1,
+			retryAfter: "1",
+			min:        time.Second,
+			max:        time.Second,
+		},
+		"malformed retry after": {
+			attempt:    1,
+			retryAfter: "soon",
+			min:        50 * time.Millisecond,
+			max:        100 * time.Millisecond,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			response := &http.Response{Header: http.Header{}}
+			if tc.retryAfter != "" {
+				response.Header.Set("Retry-After", tc.retryAfter)
+			}
+
+			wait, ok := policy.backoff(tc.attempt, response)
+			assert.True(t, ok)
+			assert.GreaterOrEqual(t, wait, tc.min)
+			assert.LessOrEqual(t, wait, tc.max)
+		})
+	}
+}
+
+func TestRetryPolicyBackoffRetryAfterTooLong(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	response := &http.Response{Header: http.Header{"Retry-After": []string{"3600"}}}
+
+	_, ok := policy.backoff(1, response)
+	assert.False(t, ok)
+
+	// Without a MaxBackoff, the server decides how long to wait
+	_, ok = RetryPolicy{}.backoff(1, response)
+	assert.True(t, ok)
+}
+
+func TestParseRetryAfterDate(t *testing.T) {
+	wait, ok := parseRetryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+
+	assert.True(t, ok)
+	assert.Greater(t, wait, 50*time.Second)
+	assert.LessOrEqual(t, wait, time.Minute)
+}
@@ -0,0 +1,64 @@
+package connect
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the name of the OpenTelemetry tracer that creates the spans of the client.
+const tracerName = "github.com/1Password/connect-sdk-go/connect"
+
+// Attributes added to the spans of requests for vaults and items.
+const (
+	vaultIDKey = attribute.Key("onepassword.vault.id")
+	itemIDKey  = attribute.Key("onepassword.item.id")
+)
+
+var resourcePathPattern = regexp.MustCompile(`^/v1/vaults/([^/?]+)(?:/items/([^/?]+))?`)
+
+// startSpan starts a span for the given operation as a child of the span carried by ctx, if any. The returned
+// context also carries the operation name for OperationName.
+func (rs *restClient) startSpan(ctx context.Context, operationName string) (trace.Span, context.Context) {
+	ctx, span := rs.tracer.Start(withOperationName(ctx, operationName), operationName, trace.WithSpanKind(trace.SpanKindClient))
+	return span, ctx
+}
+
+// traceRequest describes the request in the span and propagates the span's context to Connect using the
+// W3C Trace Context headers.
+func (rs *restClient) traceRequest(span trace.Span, request *http.Request, path string) {
+	span.SetAttributes(
+		semconv.HTTPMethod(request.Method),
+		semconv.HTTPURL(request.URL.String()),
+	)
+	if match := resourcePathPattern.FindStringSubmatch(path); match != nil {
+		span.SetAttributes(vaultIDKey.String(match[1]))
+		if match[2] != "" {
+			span.SetAttributes(itemIDKey.String(match[2]))
+		}
+	}
+	rs.propagator.Inject(request.Context(), propagation.HeaderCarrier(request.Header))
+}
+
+// traceResponse records the outcome of a request in the span carried by the request's context.
+// statusCode is 0 if no response was received.
+func traceResponse(request *http.Request, statusCode int, err error) {
+	span := trace.SpanFromContext(request.Context())
+	if statusCode != 0 {
+		span.SetAttributes(semconv.HTTPStatusCode(statusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	if statusCode >= http.StatusBadRequest {
+		span.SetStatus(codes.Error, http.StatusText(statusCode))
+	}
+}
@@ -0,0 +1,130 @@
+package connect
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTracingTestClient(dofunc func(req *http.Request) (*http.Response, error), opts ...ClientOption) Client {
+	opts = append([]ClientOption{WithHTTPClient(&mockClient{Dofunc: dofunc})}, opts...)
+	return NewClient(validHost, validToken, opts...)
+}
+
+func TestTracerProvider(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var traceparent string
+	client := newTracingTestClient(func(req *http.Request) (*http.Response, error) {
+		traceparent = req.Header.Get("traceparent")
+		return getItem(req)
+	}, WithTracerProvider(provider))
+
+	_, err := client.GetItemByUUID(testItemUUID, testVaultUUID)
+	assert.Nil(t, err)
+
+	spans := recorder.Ended()
+	if !assert.Len(t, spans, 1) {
+		return
+	}
+	span := spans[0]
+	assert.Equal(t, "GetItemByUUID", span.Name())
+	assert.Equal(t, trace.SpanKindClient, span.SpanKind())
+	assert.Equal(t, tracerName, span.InstrumentationScope().Name)
+	assert.Subset(t, span.Attributes(), []attribute.KeyValue{
+		semconv.HTTPMethod(http.MethodGet),
+		semconv.HTTPStatusCode(http.StatusOK),
+		vaultIDKey.String(testVaultUUID),
+		itemIDKey.String(testItemUUID),
+	})
+
+	expected := "00-" + span.SpanContext().TraceID().String() + "-" + span.SpanContext().SpanID().String() + "-01"
+	assert.Equal(t, expected, traceparent)
+}
+
+func TestTracingNestedOperations(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := newTracingTestClient(listVaults, WithTracerProvider(provider))
+
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	_, err := client.GetVaultWithContext(ctx, "Test vault")
+	parent.End()
+	assert.Nil(t, err)
+
+	for _, span := range recorder.Ended() {
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+	}
+	assert.Len(t, recorder.Ended(), 4)
+}
+
+func TestTracingErrorStatus(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := newTracingTestClient(respondError(apiError(http.StatusNotFound, "Vault not found")), WithTracerProvider(provider))
+
+	_, err := client.GetVaultByUUID(testVaultUUID)
+	assert.NotNil(t, err)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Contains(t, spans[0].Attributes(), semconv.HTTPStatusCode(http.StatusNotFound))
+	}
+}
+
+func TestTracingRetriesExhausted(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := newTracingTestClient(respondError(apiError(http.StatusServiceUnavailable, "unavailable")),
+		WithTracerProvider(provider), WithRetryPolicy(testRetryPolicy))
+
+	_, err := client.GetVaultByUUID(testVaultUUID)
+	assert.NotNil(t, err)
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 1) {
+		assert.Equal(t, codes.Error, spans[0].Status().Code)
+		assert.Contains(t, spans[0].Attributes(), semconv.HTTPStatusCode(http.StatusServiceUnavailable))
+	}
+}
+
+func TestTracingDisabled(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var traceparent string
+	client := newTracingTestClient(func(req *http.Request) (*http.Response, error) {
+		traceparent = req.Header.Get("traceparent")
+		return listVaults(req)
+	}, WithTracerProvider(provider), WithTracingDisabled())
+
+	// The trace context of the caller is not propagated either
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	_, err := client.GetVaultsWithContext(ctx)
+	parent.End()
+
+	assert.Nil(t, err)
+	assert.Empty(t, traceparent)
+	assert.Len(t, recorder.Ended(), 1)
+}
+
+func TestNewClientHasNoGlobalSideEffects(t *testing.T) {
+	provider := otel.GetTracerProvider()
+	propagator := otel.GetTextMapPropagator()
+
+	NewClient(validHost, validToken)
+
+	assert.Equal(t, provider, otel.GetTracerProvider())
+	assert.Equal(t, propagator, otel.GetTextMapPropagator())
+}
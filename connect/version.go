@@ -14,18 +14,16 @@ const SDKVersion = "1.5.3"
 
 const VersionHeaderKey = "1Password-Connect-Version"
 
-// expectMinimumConnectVersion returns an error if the provided minimum version for Connect is lower than the version
-// reported in the response from Connect.
-func expectMinimumConnectVersion(resp *http.Response, minimumVersion version) error {
+// expectMinimumConnectVersion returns an *UnsupportedServerVersionError if the version reported in the response
+// from Connect does not support the given capability. It covers servers whose version could not be determined
+// before the request was made.
+func expectMinimumConnectVersion(resp *http.Response, capability Capability) error {
 	serverVersion, err := getServerVersion(resp)
 	if err != nil {
 		// Return gracefully if server version cannot be determined reliably
 		return nil
 	}
-	if !serverVersion.IsGreaterOrEqualThan(minimumVersion) {
-		return fmt.Errorf("need at least version %s of Connect for this function, detected version %s. Please update your Connect server", minimumVersion, serverVersion)
-	}
-	return nil
+	return checkCapability(capability, serverVersion)
 }
 
 func getServerVersion(resp *http.Response) (serverVersion, error) {
@@ -40,6 +38,20 @@ func getServerVersion(resp *http.Response) (serverVersion, error) {
 	return parseServerVersion(versionHeader)
 }
 
+// ServerVersion is the version of a Connect server
+type ServerVersion struct {
+	Major int
+	Minor int
+	Patch int
+	// OrEarlier is true if the server did not report its version. Connect reports its version since v1.3.0,
+	// so the server runs v1.2.0 or an earlier version.
+	OrEarlier bool
+}
+
+func (v ServerVersion) String() string {
+	return serverVersion{version: version{v.Major, v.Minor, v.Patch}, orEarlier: v.OrEarlier}.String()
+}
+
 type version struct {
 	major int
 	minor int
@@ -53,6 +65,16 @@ type serverVersion struct {
 	orEarlier bool
 }
 
+// exported returns the version as a ServerVersion.
+func (v serverVersion) exported() ServerVersion {
+	return ServerVersion{
+		Major:     v.major,
+		Minor:     v.minor,
+		Patch:     v.patch,
+		OrEarlier: v.orEarlier,
+	}
+}
+
 func (v version) String() string {
 	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
 }
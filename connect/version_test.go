@@ -101,24 +101,34 @@ func TestGetServerVersion(t *testing.T) {
 
 func TestExpectMinimumVersion(t *testing.T) {
 	cases := map[string]struct {
-		minimumVersion version
-		headerValue    string
-		expectErr      bool
+		capability  Capability
+		headerValue string
+		expectErr   bool
 	}{
 		"above minimum version": {
-			minimumVersion: version{1, 2, 3},
-			headerValue:    "1.3.0",
-			expectErr:      false,
+			capability:  CapPatch,
+			headerValue: "1.3.0",
+			expectErr:   false,
 		},
 		"below minimum version": {
-			minimumVersion: version{1, 2, 3},
-			headerValue:    "1.1.0",
-			expectErr:      true,
+			capability:  CapPatch,
+			headerValue: "1.1.0",
+			expectErr:   true,
 		},
 		"illegal version provided": {
-			minimumVersion: version{1, 2, 3},
-			headerValue:    "a",
-			expectErr:      false,
+			capability:  CapPatch,
+			headerValue: "a",
+			expectErr:   false,
+		},
+		"exactly the minimum version": {
+			capability:  CapFiles,
+			headerValue: "1.3.0",
+			expectErr:   false,
+		},
+		"no version provided": {
+			capability:  CapPatch,
+			headerValue: "",
+			expectErr:   true,
 		},
 	}
 
@@ -130,12 +140,17 @@ func TestExpectMinimumVersion(t *testing.T) {
 				Header: header,
 			}
 
-			err := expectMinimumConnectVersion(resp, tc.minimumVersion)
+			err := expectMinimumConnectVersion(resp, tc.capability)
 			if tc.expectErr {
-				assert.Error(t, err)
+				assert.ErrorIs(t, err, ErrUnsupportedServerVersion)
 			} else {
 				assert.Nil(t, err)
 			}
 		})
 	}
 }
+
+func TestServerVersion_String(t *testing.T) {
+	assert.Equal(t, "1.5.7", ServerVersion{Major: 1, Minor: 5, Patch: 7}.String())
+	assert.Equal(t, "1.2.0 (or earlier)", ServerVersion{Major: 1, Minor: 2, OrEarlier: true}.String())
+}
@@ -0,0 +1,99 @@
+// Package filter builds the SCIM filter expressions Connect accepts to narrow down the vaults and items it lists.
+//
+//	f := filter.And(filter.Tag().Eq("production"), filter.Title().Sw("db-"))
+package filter
+
+import (
+	"strings"
+)
+
+// Filter is a filter expression. The zero value matches everything.
+type Filter struct {
+	expr string
+	// compound is set for expressions combining other expressions, which need parentheses when nested
+	compound bool
+}
+
+// String returns the filter in the SCIM filter syntax, as it is sent to Connect.
+func (f Filter) String() string {
+	return f.expr
+}
+
+// IsZero reports whether the filter is empty and matches everything.
+func (f Filter) IsZero() bool {
+	return f.expr == ""
+}
+
+// Attribute is an attribute of a vault or item that can be filtered on.
+type Attribute struct {
+	name string
+}
+
+// Title is the title of a vault or item.
+func Title() Attribute {
+	return Attribute{name: "title"}
+}
+
+// Tag is any of the tags of an item.
+func Tag() Attribute {
+	return Attribute{name: "tag"}
+}
+
+// Eq matches if the attribute is equal to value.
+func (a Attribute) Eq(value string) Filter {
+	return a.compare("eq", value)
+}
+
+// Co matches if the attribute contains value.
+func (a Attribute) Co(value string) Filter {
+	return a.compare("co", value)
+}
+
+// Sw matches if the attribute starts with value.
+func (a Attribute) Sw(value string) Filter {
+	return a.compare("sw", value)
+}
+
+func (a Attribute) compare(operator string, value string) Filter {
+	return Filter{expr: a.name + " " + operator + " " + quote(value)}
+}
+
+// And matches if all the given filters match. Empty filters are left out.
+func And(filters ...Filter) Filter {
+	return combine("and", filters)
+}
+
+// Or matches if any of the given filters matches. Empty filters are left out.
+func Or(filters ...Filter) Filter {
+	return combine("or", filters)
+}
+
+func combine(operator string, filters []Filter) Filter {
+	var operands []Filter
+	for _, f := range filters {
+		if !f.IsZero() {
+			operands = append(operands, f)
+		}
+	}
+
+	switch len(operands) {
+	case 0:
+		return Filter{}
+	case 1:
+		return operands[0]
+	}
+
+	exprs := make([]string, len(operands))
+	for i, f := range operands {
+		exprs[i] = f.expr
+		if f.compound {
+			exprs[i] = "(" + f.expr + ")"
+		}
+	}
+	return Filter{expr: strings.Join(exprs, " "+operator+" "), compound: true}
+}
+
+// quote returns value as a SCIM string literal, escaping backslashes and double quotes.
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
@@ -0,0 +1,67 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	cases := map[string]struct {
+		filter   Filter
+		expected string
+	}{
+		"title equals": {
+			filter:   Title().Eq("Database"),
+			expected: `title eq "Database"`,
+		},
+		"title contains": {
+			filter:   Title().Co("base"),
+			expected: `title co "base"`,
+		},
+		"title starts with": {
+			filter:   Title().Sw("db-"),
+			expected: `title sw "db-"`,
+		},
+		"tag equals": {
+			filter:   Tag().Eq("production"),
+			expected: `tag eq "production"`,
+		},
+		"escaped quotes and backslashes": {
+			filter:   Title().Eq(`say "hi" \o/`),
+			expected: `title eq "say \"hi\" \\o/"`,
+		},
+		"and": {
+			filter:   And(Tag().Eq("a"), Title().Sw("b")),
+			expected: `tag eq "a" and title sw "b"`,
+		},
+		"or nested in and": {
+			filter:   And(Tag().Eq("a"), Or(Title().Eq("b"), Title().Eq("c"))),
+			expected: `tag eq "a" and (title eq "b" or title eq "c")`,
+		},
+		"empty filters left out": {
+			filter:   Or(Filter{}, Tag().Eq("a"), And()),
+			expected: `tag eq "a"`,
+		},
+		"single compound operand": {
+			filter:   And(Or(Tag().Eq("a"), Tag().Eq("b"))),
+			expected: `tag eq "a" or tag eq "b"`,
+		},
+		"empty": {
+			filter:   And(),
+			expected: "",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.filter.String())
+		})
+	}
+}
+
+func TestFilterIsZero(t *testing.T) {
+	assert.True(t, Filter{}.IsZero())
+	assert.True(t, Or().IsZero())
+	assert.False(t, Tag().Eq("").IsZero())
+}
@@ -0,0 +1,66 @@
+package onepassword
+
+import "time"
+
+// APIRequest represents a request that was made to the Connect API
+type APIRequest struct {
+	RequestID string             `json:"requestId"`
+	Timestamp time.Time          `json:"timestamp"`
+	Action    APIRequestAction   `json:"action"`
+	Result    APIRequestResult   `json:"result"`
+	Actor     APIRequestActor    `json:"actor"`
+	Resource  APIRequestResource `json:"resource"`
+}
+
+// APIRequestAction Representation of the action of an API request
+type APIRequestAction string
+
+const (
+	APIRequestActionRead   APIRequestAction = "READ"
+	APIRequestActionCreate APIRequestAction = "CREATE"
+	APIRequestActionUpdate APIRequestAction = "UPDATE"
+	APIRequestActionDelete APIRequestAction = "DELETE"
+)
+
+// APIRequestResult Representation of the result of an API request
+type APIRequestResult string
+
+const (
+	APIRequestResultSuccess APIRequestResult = "SUCCESS"
+	APIRequestResultDeny    APIRequestResult = "DENY"
+)
+
+// APIRequestActor represents the token and client that made an API request
+type APIRequestActor struct {
+	ID        string `json:"id"`
+	Account   string `json:"account"`
+	JTI       string `json:"jti"`
+	UserAgent string `json:"userAgent"`
+	RequestIP string `json:"requestIp"`
+}
+
+// APIRequestResource represents the vault or item that an API request accessed
+type APIRequestResource struct {
+	Type        APIRequestResourceType `json:"type"`
+	Vault       APIRequestVault        `json:"vault"`
+	Item        APIRequestItem         `json:"item"`
+	ItemVersion int                    `json:"itemVersion,omitempty"`
+}
+
+// APIRequestResourceType Representation of the kind of resource an API request accessed
+type APIRequestResourceType string
+
+const (
+	APIRequestResourceTypeItem  APIRequestResourceType = "ITEM"
+	APIRequestResourceTypeVault APIRequestResourceType = "VAULT"
+)
+
+// APIRequestVault references the vault accessed by an API request
+type APIRequestVault struct {
+	ID string `json:"id"`
+}
+
+// APIRequestItem references the item accessed by an API request
+type APIRequestItem struct {
+	ID string `json:"id"`
+}
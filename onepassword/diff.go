@@ -0,0 +1,155 @@
+package onepassword
+
+import (
+	"errors"
+	"reflect"
+)
+
+// DiffItems returns the patch operations that turn the original item into the modified one. Fields and sections
+// are matched by their ID. A field or section whose ID is not found in the original item is added, unless it has
+// no ID and the original item contains an identical one.
+// Applying the operations only changes the attributes that differ between both items, so changes made to other
+// attributes of the item in the meantime are preserved. An error is returned if either item is nil.
+func DiffItems(original *Item, modified *Item) ([]PatchOperation, error) {
+	if original == nil || modified == nil {
+		return nil, errors.New("cannot diff a nil item")
+	}
+
+	var ops []PatchOperation
+
+	if original.Title != modified.Title {
+		ops = append(ops, ReplaceOperation(PatchPathTitle, modified.Title))
+	}
+	if original.Favorite != modified.Favorite {
+		ops = append(ops, ReplaceOperation(PatchPathFavorite, modified.Favorite))
+	}
+	if !reflect.DeepEqual(nonNilTags(original.Tags), nonNilTags(modified.Tags)) {
+		ops = append(ops, ReplaceOperation(PatchPathTags, nonNilTags(modified.Tags)))
+	}
+	if !reflect.DeepEqual(nonNilURLs(original.URLs), nonNilURLs(modified.URLs)) {
+		ops = append(ops, ReplaceOperation(PatchPathURLs, nonNilURLs(modified.URLs)))
+	}
+
+	originalSections := sectionsByID(original.Sections)
+	modifiedSections := sectionsByID(modified.Sections)
+	originalFields := fieldsByID(original.Fields)
+	modifiedFields := fieldsByID(modified.Fields)
+
+	// New sections are added before the fields, as new fields may be placed in them
+	for _, s := range modified.Sections {
+		if s == nil {
+			continue
+		}
+		o, found := originalSections[s.ID]
+		if s.ID == "" {
+			if !containsSection(original.Sections, s) {
+				ops = append(ops, AddOperation(PatchPathSections, s))
+			}
+		} else if !found {
+			ops = append(ops, AddOperation(PatchPathSections, s))
+		} else if o.Label != s.Label {
+			ops = append(ops, ReplaceOperation(SectionPath(s.ID, "label"), s.Label))
+		}
+	}
+
+	for _, f := range original.Fields {
+		if f == nil || f.ID == "" {
+			continue
+		}
+		if _, found := modifiedFields[f.ID]; !found {
+			ops = append(ops, RemoveOperation(FieldPath(f.ID)))
+		}
+	}
+	for _, f := range modified.Fields {
+		if f == nil {
+			continue
+		}
+		o, found := originalFields[f.ID]
+		if f.ID == "" {
+			if !containsField(original.Fields, f) {
+				ops = append(ops, AddOperation(PatchPathFields, f))
+			}
+			continue
+		}
+		if !found {
+			ops = append(ops, AddOperation(PatchPathFields, f))
+			continue
+		}
+		if reflect.DeepEqual(o, f) {
+			continue
+		}
+		withOriginalValue := *f
+		withOriginalValue.Value = o.Value
+		if reflect.DeepEqual(*o, withOriginalValue) {
+			ops = append(ops, ReplaceOperation(FieldPath(f.ID, "value"), f.Value))
+		} else {
+			ops = append(ops, ReplaceOperation(FieldPath(f.ID), f))
+		}
+	}
+
+	// Sections are removed after the fields, so that no field is left in a removed section
+	for _, s := range original.Sections {
+		if s == nil || s.ID == "" {
+			continue
+		}
+		if _, found := modifiedSections[s.ID]; !found {
+			ops = append(ops, RemoveOperation(SectionPath(s.ID)))
+		}
+	}
+
+	return ops, nil
+}
+
+func sectionsByID(sections []*ItemSection) map[string]*ItemSection {
+	byID := make(map[string]*ItemSection, len(sections))
+	for _, s := range sections {
+		if s != nil && s.ID != "" {
+			byID[s.ID] = s
+		}
+	}
+	return byID
+}
+
+func fieldsByID(fields []*ItemField) map[string]*ItemField {
+	byID := make(map[string]*ItemField, len(fields))
+	for _, f := range fields {
+		if f != nil && f.ID != "" {
+			byID[f.ID] = f
+		}
+	}
+	return byID
+}
+
+func containsSection(sections []*ItemSection, section *ItemSection) bool {
+	for _, s := range sections {
+		if reflect.DeepEqual(s, section) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsField(fields []*ItemField, field *ItemField) bool {
+	for _, f := range fields {
+		if reflect.DeepEqual(f, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// nonNilTags returns an empty slice for nil tags, so that nil and empty tags are considered equal and a removal
+// of all tags is sent as an empty list.
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+func nonNilURLs(urls []ItemURL) []ItemURL {
+	if urls == nil {
+		return []ItemURL{}
+	}
+	return urls
+}
@@ -0,0 +1,138 @@
+package onepassword
+
+import (
+	"reflect"
+	"testing"
+)
+
+func diffTestItem() *Item {
+	return &Item{
+		ID:    "2a47aa139ef74d7ca17918035e",
+		Title: "Database",
+		Tags:  []string{"prod"},
+		Vault: ItemVault{ID: "5b52aa139ef74d7ca17918nmf8"},
+		URLs: []ItemURL{
+			{Primary: true, URL: "https://db.example.com"},
+		},
+		Sections: []*ItemSection{
+			{ID: "details", Label: "Details"},
+			{ID: "legacy", Label: "Legacy"},
+		},
+		Fields: []*ItemField{
+			{ID: "username", Type: FieldTypeString, Purpose: FieldPurposeUsername, Label: "username", Value: "wendy"},
+			{ID: "password", Type: FieldTypeConcealed, Purpose: FieldPurposePassword, Label: "password", Value: "appleseed"},
+			{ID: "port", Type: FieldTypeString, Label: "port", Value: "5432", Section: &ItemSection{ID: "details"}},
+			{ID: "old", Type: FieldTypeString, Label: "old", Value: "value", Section: &ItemSection{ID: "legacy"}},
+		},
+	}
+}
+
+func TestDiffItemsNoChanges(t *testing.T) {
+	ops, err := DiffItems(diffTestItem(), diffTestItem())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 0 {
+		t.Logf("Expected no operations, found %v", ops)
+		t.FailNow()
+	}
+}
+
+func TestDiffItemsNilAndEmptyAreEqual(t *testing.T) {
+	original := diffTestItem()
+	original.Tags = nil
+	modified := diffTestItem()
+	modified.Tags = []string{}
+
+	ops, err := DiffItems(original, modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 0 {
+		t.Logf("Expected no operations, found %v", ops)
+		t.FailNow()
+	}
+}
+
+func TestDiffItems(t *testing.T) {
+	original := diffTestItem()
+	modified := diffTestItem()
+
+	modified.Title = "Production Database"
+	modified.Tags = nil
+	modified.Fields[1].Value = "n3w-p4ssw0rd"
+	modified.Fields[2].Label = "Port"
+	modified.Sections = modified.Sections[:1]
+	modified.Sections[0].Label = "Connection details"
+	modified.Sections = append(modified.Sections, &ItemSection{ID: "extra", Label: "Extra"})
+	modified.Fields = modified.Fields[:3]
+	newField := &ItemField{Type: FieldTypeString, Label: "host", Value: "db.example.com", Section: &ItemSection{ID: "extra"}}
+	modified.Fields = append(modified.Fields, newField)
+
+	expected := []PatchOperation{
+		ReplaceOperation("/title", "Production Database"),
+		ReplaceOperation("/tags", []string{}),
+		ReplaceOperation("/sections/details/label", "Connection details"),
+		AddOperation("/sections", &ItemSection{ID: "extra", Label: "Extra"}),
+		RemoveOperation("/fields/old"),
+		ReplaceOperation("/fields/password/value", "n3w-p4ssw0rd"),
+		ReplaceOperation("/fields/port", modified.Fields[2]),
+		AddOperation("/fields", newField),
+		RemoveOperation("/sections/legacy"),
+	}
+
+	ops, err := DiffItems(original, modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, ops) {
+		t.Logf("Expected operations %v, found %v", expected, ops)
+		t.FailNow()
+	}
+}
+
+func TestDiffItemsURLsAndFavorite(t *testing.T) {
+	original := diffTestItem()
+	modified := diffTestItem()
+	modified.Favorite = true
+	modified.URLs = append(modified.URLs, ItemURL{Label: "admin", URL: "https://admin.example.com"})
+
+	expected := []PatchOperation{
+		ReplaceOperation("/favorite", true),
+		ReplaceOperation("/urls", modified.URLs),
+	}
+
+	ops, err := DiffItems(original, modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(expected, ops) {
+		t.Logf("Expected operations %v, found %v", expected, ops)
+		t.FailNow()
+	}
+}
+
+func TestDiffItemsUnchangedFieldsWithoutID(t *testing.T) {
+	original := diffTestItem()
+	original.Fields = append(original.Fields, &ItemField{Type: FieldTypeString, Label: "note", Value: "unsaved"})
+	modified := diffTestItem()
+	modified.Fields = append(modified.Fields, &ItemField{Type: FieldTypeString, Label: "note", Value: "unsaved"})
+
+	ops, err := DiffItems(original, modified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ops) != 0 {
+		t.Logf("Expected no operations, found %v", ops)
+		t.FailNow()
+	}
+}
+
+func TestDiffItemsNil(t *testing.T) {
+	if _, err := DiffItems(nil, diffTestItem()); err == nil {
+		t.Error("Expected an error for a nil original item")
+	}
+	if _, err := DiffItems(diffTestItem(), nil); err == nil {
+		t.Error("Expected an error for a nil modified item")
+	}
+}
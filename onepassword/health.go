@@ -0,0 +1,16 @@
+package onepassword
+
+// ServerHealth represents the health of a Connect server, as reported by its health endpoint
+type ServerHealth struct {
+	Name         string              `json:"name"`
+	Version      string              `json:"version"`
+	Dependencies []ServiceDependency `json:"dependencies,omitempty"`
+}
+
+// ServiceDependency represents the state of a service that Connect depends on, e.g. its database or the
+// synchronization with 1Password.com
+type ServiceDependency struct {
+	Service string `json:"service"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,71 @@
+package onepassword
+
+import (
+	"strings"
+)
+
+// PatchOperationType is the type of an RFC 6902 JSON Patch operation
+type PatchOperationType string
+
+const (
+	PatchOperationAdd     PatchOperationType = "add"
+	PatchOperationRemove  PatchOperationType = "remove"
+	PatchOperationReplace PatchOperationType = "replace"
+)
+
+// Paths of the item attributes that can be patched as a whole
+const (
+	PatchPathTitle    = "/title"
+	PatchPathFavorite = "/favorite"
+	PatchPathTags     = "/tags"
+	PatchPathURLs     = "/urls"
+	PatchPathSections = "/sections"
+	PatchPathFields   = "/fields"
+)
+
+// PatchOperation Representation of a single RFC 6902 JSON Patch operation on an Item
+type PatchOperation struct {
+	Op    PatchOperationType `json:"op"`
+	Path  string             `json:"path"`
+	Value interface{}        `json:"value,omitempty"`
+}
+
+// AddOperation returns an operation that adds value at path, e.g. a new field with path PatchPathFields.
+func AddOperation(path string, value interface{}) PatchOperation {
+	return PatchOperation{Op: PatchOperationAdd, Path: path, Value: value}
+}
+
+// ReplaceOperation returns an operation that replaces the value at path.
+func ReplaceOperation(path string, value interface{}) PatchOperation {
+	return PatchOperation{Op: PatchOperationReplace, Path: path, Value: value}
+}
+
+// RemoveOperation returns an operation that removes the value at path.
+func RemoveOperation(path string) PatchOperation {
+	return PatchOperation{Op: PatchOperationRemove, Path: path}
+}
+
+// FieldPath returns the path of the field with the given ID or label. If attribute is provided, the path points to
+// that attribute of the field, e.g. FieldPath("password", "value") is "/fields/password/value".
+func FieldPath(field string, attribute ...string) string {
+	return patchPath(PatchPathFields, field, attribute)
+}
+
+// SectionPath returns the path of the section with the given ID or label. If attribute is provided, the path
+// points to that attribute of the section, e.g. SectionPath("details", "label") is "/sections/details/label".
+func SectionPath(section string, attribute ...string) string {
+	return patchPath(PatchPathSections, section, attribute)
+}
+
+func patchPath(collection string, element string, attribute []string) string {
+	path := collection + "/" + escapePatchPathSegment(element)
+	for _, a := range attribute {
+		path += "/" + escapePatchPathSegment(a)
+	}
+	return path
+}
+
+// escapePatchPathSegment escapes a reference token of a JSON Pointer, as described in RFC 6901.
+func escapePatchPathSegment(segment string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(segment)
+}
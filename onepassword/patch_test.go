@@ -0,0 +1,48 @@
+package onepassword
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPatchPaths(t *testing.T) {
+	cases := map[string]struct {
+		path     string
+		expected string
+	}{
+		"field by id":         {path: FieldPath("vy09gd8EXAMPLE"), expected: "/fields/vy09gd8EXAMPLE"},
+		"field value":         {path: FieldPath("password", "value"), expected: "/fields/password/value"},
+		"section label":       {path: SectionPath("details", "label"), expected: "/sections/details/label"},
+		"escaped slash":       {path: FieldPath("client/secret"), expected: "/fields/client~1secret"},
+		"escaped tilde":       {path: SectionPath("~home"), expected: "/sections/~0home"},
+		"escaped both orders": {path: FieldPath("~/"), expected: "/fields/~0~1"},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if tc.path != tc.expected {
+				t.Logf("Expected path %q, found %q", tc.expected, tc.path)
+				t.FailNow()
+			}
+		})
+	}
+}
+
+func TestPatchOperationJSON(t *testing.T) {
+	ops := []PatchOperation{
+		AddOperation(PatchPathFields, &ItemField{Label: "token", Type: FieldTypeConcealed, Value: "secret"}),
+		RemoveOperation(FieldPath("username")),
+	}
+
+	data, err := json.Marshal(ops)
+	if err != nil {
+		t.Logf("Unable to marshal patch operations: %s", err)
+		t.FailNow()
+	}
+
+	expected := `[{"op":"add","path":"/fields","value":{"id":"","type":"CONCEALED","label":"token","value":"secret"}},{"op":"remove","path":"/fields/username"}]`
+	if string(data) != expected {
+		t.Logf("Expected %s, found %s", expected, data)
+		t.FailNow()
+	}
+}
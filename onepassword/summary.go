@@ -0,0 +1,40 @@
+package onepassword
+
+import (
+	"context"
+	"time"
+)
+
+// ItemSummary represents the overview of an item returned when listing the items in a vault. Unlike an Item, it
+// has no sections, fields or files. Use Load to get the full item.
+type ItemSummary struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+
+	URLs     []ItemURL `json:"urls,omitempty"`
+	Favorite bool      `json:"favorite,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+	Version  int       `json:"version,omitempty"`
+
+	Vault    ItemVault    `json:"vault"`
+	Category ItemCategory `json:"category,omitempty"`
+
+	LastEditedBy string    `json:"lastEditedBy,omitempty"`
+	CreatedAt    time.Time `json:"createdAt,omitempty"`
+	UpdatedAt    time.Time `json:"updatedAt,omitempty"`
+}
+
+// ItemLoader gets a full item by its UUID. It is implemented by the clients in the connect package.
+type ItemLoader interface {
+	GetItemByUUIDWithContext(ctx context.Context, uuid string, vaultQuery string) (*Item, error)
+}
+
+// Load Get the full item the summary describes, including its sections, fields and files
+func (s ItemSummary) Load(loader ItemLoader) (*Item, error) {
+	return s.LoadWithContext(context.Background(), loader)
+}
+
+// LoadWithContext Get the full item the summary describes, including its sections, fields and files
+func (s ItemSummary) LoadWithContext(ctx context.Context, loader ItemLoader) (*Item, error) {
+	return loader.GetItemByUUIDWithContext(ctx, s.ID, s.Vault.ID)
+}
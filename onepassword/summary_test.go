@@ -0,0 +1,28 @@
+package onepassword
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type loaderFunc func(ctx context.Context, uuid string, vaultQuery string) (*Item, error)
+
+func (f loaderFunc) GetItemByUUIDWithContext(ctx context.Context, uuid string, vaultQuery string) (*Item, error) {
+	return f(ctx, uuid, vaultQuery)
+}
+
+func TestItemSummaryLoad(t *testing.T) {
+	summary := ItemSummary{ID: "item-id", Title: "Login", Vault: ItemVault{ID: "vault-id"}}
+	loader := loaderFunc(func(ctx context.Context, uuid string, vaultQuery string) (*Item, error) {
+		assert.Equal(t, "item-id", uuid)
+		assert.Equal(t, "vault-id", vaultQuery)
+		return testLogin(), nil
+	})
+
+	item, err := summary.Load(loader)
+
+	assert.NoError(t, err)
+	assert.Equal(t, testPassword, item.GetValue("password"))
+}